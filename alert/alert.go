@@ -0,0 +1,424 @@
+// Package alert forwards error entries of go-logger to a webhook (Slack compatible, Telegram or a custom JSON template).
+package alert
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	goLoggerAppName "github.com/pobyzaarif/go-logger/appname"
+	goLogger "github.com/pobyzaarif/go-logger/logger"
+)
+
+type (
+	Format int
+
+	// Config defines the config for Notifier.
+	Config struct {
+		// URL is the webhook url, for Telegram "https://api.telegram.org/bot<token>/sendMessage".
+		URL string
+
+		// Format is the payload format.
+		// Optional. Default value SlackFormat.
+		Format Format
+
+		// ChatID is the Telegram chat receiving the alerts.
+		// Required for TelegramFormat.
+		ChatID string
+
+		// Template renders the JSON payload of TemplateFormat, executed with TemplateData.
+		// The "json" function encodes a value as JSON, e.g. {"text": {{json .Text}}}.
+		// Required for TemplateFormat.
+		Template string
+
+		// MinLevel is the lowest level forwarded.
+		// Optional. Default value goLogger.ErrorLevel.
+		MinLevel goLogger.Level
+
+		// Throttle is the minimum time between two alerts of the same fingerprint,
+		// the alerts in between are counted and reported with the next one.
+		// Optional. Default value 1 minute.
+		Throttle time.Duration
+
+		// BatchSize is the maximum number of alerts sent in one request.
+		// Optional. Default value 10.
+		BatchSize int
+
+		// BatchInterval is how long alerts are gathered before being sent.
+		// Optional. Default value 5 seconds.
+		BatchInterval time.Duration
+
+		// QueueSize is the number of alerts waiting to be sent, new alerts are dropped beyond it.
+		// Optional. Default value 1000.
+		QueueSize int
+
+		// MaxRetries is the number of retries of a failed request, the batch is dropped after.
+		// Optional. Default value 3.
+		MaxRetries int
+
+		// RetryBackoff is the wait before the first retry, doubled on each retry.
+		// Optional. Default value 1 second.
+		RetryBackoff time.Duration
+
+		// Client sends the webhook requests.
+		// Optional. Default value an http.Client with a 5 seconds timeout.
+		Client *http.Client
+	}
+
+	// Alert is an entry forwarded to the webhook.
+	Alert struct {
+		Time        time.Time              `json:"time"`
+		Level       string                 `json:"level"`
+		Tag         string                 `json:"tag"`
+		Message     string                 `json:"message"`
+		TrackerID   string                 `json:"tracker_id"`
+		Error       string                 `json:"error"`
+		Fingerprint string                 `json:"fingerprint"`
+		Suppressed  int                    `json:"suppressed"`
+		Data        map[string]interface{} `json:"data"`
+	}
+
+	// TemplateData is the value the Template of TemplateFormat is executed with.
+	TemplateData struct {
+		Service string
+		Alerts  []Alert
+		// Text is the alerts formatted as plain text.
+		Text string
+	}
+
+	// Notifier forwards entries to the webhook, register it with goLogger.AddHook(notifier.Hook).
+	Notifier struct {
+		config    Config
+		template  *template.Template
+		queue     chan Alert
+		lastSent  map[string]time.Time
+		throttled map[string]int
+		done      chan struct{}
+		closeOnce sync.Once
+		wg        sync.WaitGroup
+		mutex     sync.Mutex
+	}
+
+	batch struct {
+		alerts  []Alert
+		retries int
+		next    time.Time
+	}
+)
+
+// Format possible values
+const (
+	SlackFormat Format = iota
+	TelegramFormat
+	TemplateFormat
+)
+
+// tag of the entries logged by the notifier itself, never forwarded
+const alertTag = "GOLOGGER_ALERT"
+
+var (
+	// DefaultConfig is the default Notifier config.
+	DefaultConfig = Config{
+		Format:        SlackFormat,
+		MinLevel:      goLogger.ErrorLevel,
+		Throttle:      time.Minute,
+		BatchSize:     10,
+		BatchInterval: 5 * time.Second,
+		QueueSize:     1000,
+		MaxRetries:    3,
+		RetryBackoff:  time.Second,
+	}
+
+	service = goLoggerAppName.GetAPPName()
+	logger  = goLogger.NewLog(alertTag)
+)
+
+// New creates a Notifier and starts its sending goroutine, Close stops it.
+func New(config Config) (*Notifier, error) {
+	if config.URL == "" {
+		return nil, errors.New("alert: url is required")
+	}
+	if config.Format == TelegramFormat && config.ChatID == "" {
+		return nil, errors.New("alert: chat id is required for telegram format")
+	}
+	if config.MinLevel == 0 {
+		config.MinLevel = DefaultConfig.MinLevel
+	}
+	if config.Throttle <= 0 {
+		config.Throttle = DefaultConfig.Throttle
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = DefaultConfig.BatchSize
+	}
+	if config.BatchInterval <= 0 {
+		config.BatchInterval = DefaultConfig.BatchInterval
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = DefaultConfig.QueueSize
+	}
+	if config.MaxRetries <= 0 {
+		config.MaxRetries = DefaultConfig.MaxRetries
+	}
+	if config.RetryBackoff <= 0 {
+		config.RetryBackoff = DefaultConfig.RetryBackoff
+	}
+	if config.Client == nil {
+		config.Client = &http.Client{Timeout: 5 * time.Second}
+	}
+
+	n := &Notifier{
+		config:    config,
+		queue:     make(chan Alert, config.QueueSize),
+		lastSent:  make(map[string]time.Time),
+		throttled: make(map[string]int),
+		done:      make(chan struct{}),
+	}
+
+	if config.Format == TemplateFormat {
+		t, err := template.New("alert").Funcs(template.FuncMap{
+			"json": func(v interface{}) (string, error) {
+				b, err := json.Marshal(v)
+				return string(b), err
+			},
+		}).Parse(config.Template)
+		if err != nil {
+			return nil, fmt.Errorf("alert: invalid template: %w", err)
+		}
+		n.template = t
+	}
+
+	n.wg.Add(1)
+	go n.run()
+
+	return n, nil
+}
+
+// Hook is a goLogger.Hook queueing the entries at or above MinLevel, it never drops the entry.
+// Fatal entries are sent before returning, bounded by the timeout of Client, and never throttled.
+func (n *Notifier) Hook(entry *goLogger.Entry) bool {
+	if entry.Level < n.config.MinLevel || entry.Tag == alertTag {
+		return true
+	}
+
+	alert := Alert{
+		Time:        time.Now(),
+		Level:       entry.Level.String(),
+		Tag:         entry.Tag,
+		Message:     entry.Message,
+		TrackerID:   entry.TrackerID,
+		Fingerprint: fingerprint(entry),
+		Data:        copyData(entry.Data),
+	}
+	if entry.Error != nil {
+		alert.Error = entry.Error.Error()
+	}
+
+	// the process exits right after a fatal entry, send it now instead of queueing it
+	if entry.Level == goLogger.FatalLevel {
+		if err := n.post([]Alert{alert}); err != nil {
+			logger.WarnWithDataAndError("fatal alert dropped", map[string]interface{}{"tag": alert.Tag}, err)
+		}
+		return true
+	}
+
+	n.mutex.Lock()
+	if last, ok := n.lastSent[alert.Fingerprint]; ok && alert.Time.Sub(last) < n.config.Throttle {
+		n.throttled[alert.Fingerprint]++
+		n.mutex.Unlock()
+		return true
+	}
+	n.lastSent[alert.Fingerprint] = alert.Time
+	alert.Suppressed = n.throttled[alert.Fingerprint]
+	delete(n.throttled, alert.Fingerprint)
+	n.mutex.Unlock()
+
+	select {
+	case n.queue <- alert:
+	default:
+		// queue is full, drop rather than block the logging path
+	}
+
+	return true
+}
+
+// copyData returns a copy of data, which belongs to the caller, so the alert can be encoded later on the sending goroutine.
+func copyData(data map[string]interface{}) map[string]interface{} {
+	if data == nil {
+		return nil
+	}
+
+	b, err := json.Marshal(data)
+	if err != nil {
+		return map[string]interface{}{"error": fmt.Sprintf("unencodable data: %v", err)}
+	}
+	copied := make(map[string]interface{})
+	if err := json.Unmarshal(b, &copied); err != nil {
+		return map[string]interface{}{"error": fmt.Sprintf("unencodable data: %v", err)}
+	}
+	return copied
+}
+
+// Close sends the queued alerts (without retrying) and stops the notifier.
+func (n *Notifier) Close() error {
+	n.closeOnce.Do(func() {
+		close(n.done)
+	})
+	n.wg.Wait()
+	return nil
+}
+
+func (n *Notifier) run() {
+	defer n.wg.Done()
+
+	ticker := time.NewTicker(n.config.BatchInterval)
+	defer ticker.Stop()
+
+	var pending []Alert
+	var retries []*batch
+
+	for {
+		select {
+		case alert := <-n.queue:
+			pending = append(pending, alert)
+			if len(pending) >= n.config.BatchSize {
+				retries = n.send(&batch{alerts: pending}, retries)
+				pending = nil
+			}
+		case <-ticker.C:
+			if len(pending) > 0 {
+				retries = n.send(&batch{alerts: pending}, retries)
+				pending = nil
+			}
+			retries = n.retry(retries)
+			n.forget()
+		case <-n.done:
+			for {
+				select {
+				case alert := <-n.queue:
+					pending = append(pending, alert)
+					continue
+				default:
+				}
+				break
+			}
+			for len(pending) > 0 {
+				size := n.config.BatchSize
+				if size > len(pending) {
+					size = len(pending)
+				}
+				_ = n.post(pending[:size])
+				pending = pending[size:]
+			}
+			return
+		}
+	}
+}
+
+// send posts b and appends it to the retry queue on failure.
+func (n *Notifier) send(b *batch, retries []*batch) []*batch {
+	err := n.post(b.alerts)
+	if err == nil {
+		return retries
+	}
+
+	if b.retries >= n.config.MaxRetries || len(retries) >= n.config.QueueSize/n.config.BatchSize+1 {
+		logger.WarnWithDataAndError("alert dropped", map[string]interface{}{"alerts": len(b.alerts), "retries": b.retries}, err)
+		return retries
+	}
+
+	b.next = time.Now().Add(n.config.RetryBackoff << b.retries)
+	b.retries++
+	return append(retries, b)
+}
+
+func (n *Notifier) retry(retries []*batch) []*batch {
+	var remaining []*batch
+	now := time.Now()
+	for _, b := range retries {
+		if now.Before(b.next) {
+			remaining = append(remaining, b)
+			continue
+		}
+		remaining = n.send(b, remaining)
+	}
+	return remaining
+}
+
+// forget drops the throttling state of fingerprints not seen for a throttle period.
+func (n *Notifier) forget() {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	now := time.Now()
+	for fp, last := range n.lastSent {
+		if now.Sub(last) >= n.config.Throttle && n.throttled[fp] == 0 {
+			delete(n.lastSent, fp)
+		}
+	}
+}
+
+func (n *Notifier) post(alerts []Alert) error {
+	body, err := n.payload(alerts)
+	if err != nil {
+		return err
+	}
+
+	res, err := n.config.Client.Post(n.config.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("alert: webhook responded %s", res.Status)
+	}
+	return nil
+}
+
+func (n *Notifier) payload(alerts []Alert) ([]byte, error) {
+	text := formatText(alerts)
+
+	switch n.config.Format {
+	case TelegramFormat:
+		return json.Marshal(map[string]interface{}{"chat_id": n.config.ChatID, "text": text})
+	case TemplateFormat:
+		var buffer bytes.Buffer
+		err := n.template.Execute(&buffer, TemplateData{Service: service, Alerts: alerts, Text: text})
+		return buffer.Bytes(), err
+	default:
+		return json.Marshal(map[string]interface{}{"text": text})
+	}
+}
+
+func formatText(alerts []Alert) string {
+	var sb strings.Builder
+	for i, alert := range alerts {
+		if i > 0 {
+			sb.WriteString("\n\n")
+		}
+		fmt.Fprintf(&sb, "[%s] %s %s: %s", alert.Level, service, alert.Tag, alert.Message)
+		if alert.Error != "" {
+			fmt.Fprintf(&sb, "\nerror: %s", alert.Error)
+		}
+		if alert.TrackerID != "" {
+			fmt.Fprintf(&sb, "\ntracker_id: %s", alert.TrackerID)
+		}
+		if alert.Suppressed > 0 {
+			fmt.Fprintf(&sb, "\n(%d similar alerts suppressed)", alert.Suppressed)
+		}
+	}
+	return sb.String()
+}
+
+func fingerprint(entry *goLogger.Entry) string {
+	if entry.Fingerprint != "" {
+		return entry.Fingerprint
+	}
+	return goLogger.Fingerprint(entry.Tag, entry.Message, nil, nil)
+}
@@ -0,0 +1,152 @@
+package alert
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	goLogger "github.com/pobyzaarif/go-logger/logger"
+)
+
+// webhook is a local stand-in of the alert webhook recording the payloads it receives.
+type webhook struct {
+	*httptest.Server
+	mutex    sync.Mutex
+	payloads []map[string]interface{}
+}
+
+func newWebhook(t *testing.T) *webhook {
+	w := &webhook{}
+	w.Server = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var payload map[string]interface{}
+		if err := json.Unmarshal(body, &payload); err != nil {
+			t.Errorf("invalid payload %s: %v", body, err)
+		}
+		w.mutex.Lock()
+		w.payloads = append(w.payloads, payload)
+		w.mutex.Unlock()
+	}))
+	t.Cleanup(w.Close)
+	return w
+}
+
+func (w *webhook) texts() []string {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	var texts []string
+	for _, payload := range w.payloads {
+		text, _ := payload["text"].(string)
+		texts = append(texts, text)
+	}
+	return texts
+}
+
+func TestNotifierSendsErrorsOnClose(t *testing.T) {
+	goLogger.ReplaceGlobalsForTest(t)
+	hook := newWebhook(t)
+
+	notifier, err := New(Config{URL: hook.URL, BatchInterval: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(goLogger.AddHook(notifier.Hook))
+
+	log := goLogger.NewLog("PAYMENT")
+	log.Info("not forwarded")
+	for i := 0; i < 3; i++ {
+		log.Error("charge failed", errors.New("card declined"))
+	}
+	log.Error("refund failed", errors.New("timeout"))
+	notifier.Close()
+
+	texts := strings.Join(hook.texts(), "\n")
+	if strings.Contains(texts, "not forwarded") {
+		t.Errorf("info entry forwarded: %s", texts)
+	}
+	if n := strings.Count(texts, "charge failed"); n != 1 {
+		t.Errorf("got %d throttled alerts, want 1: %s", n, texts)
+	}
+	if !strings.Contains(texts, "refund failed") || !strings.Contains(texts, "error: timeout") {
+		t.Errorf("missing refund alert: %s", texts)
+	}
+}
+
+func TestNotifierTemplateFormat(t *testing.T) {
+	goLogger.ReplaceGlobalsForTest(t)
+	hook := newWebhook(t)
+
+	notifier, err := New(Config{URL: hook.URL, Format: TemplateFormat, Template: `{"text": {{json (index .Alerts 0).Tag}}}`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(goLogger.AddHook(notifier.Hook))
+
+	log := goLogger.NewLog("PAYMENT")
+	log.Error("charge failed", errors.New("card declined"))
+	notifier.Close()
+
+	if texts := hook.texts(); len(texts) != 1 || texts[0] != "PAYMENT" {
+		t.Errorf("got payload texts %q, want [PAYMENT]", texts)
+	}
+}
+
+func TestNotifierCopiesEntryData(t *testing.T) {
+	goLogger.ReplaceGlobalsForTest(t)
+	hook := newWebhook(t)
+
+	notifier, err := New(Config{URL: hook.URL, Format: TemplateFormat, Template: `{"text": {{json (json .Alerts)}}}`, BatchInterval: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(goLogger.AddHook(notifier.Hook))
+
+	data := map[string]interface{}{"order_id": "1"}
+	log := goLogger.NewLog("PAYMENT")
+	log.ErrorWithData("charge failed", data, errors.New("card declined"))
+	// the caller keeps using its map while the notifier encodes the alert
+	for i := 0; i < 1000; i++ {
+		data["order_id"] = "changed"
+		time.Sleep(10 * time.Microsecond)
+	}
+	notifier.Close()
+
+	texts := hook.texts()
+	if len(texts) != 1 || !strings.Contains(texts[0], `"order_id":"1"`) {
+		t.Errorf("got payload texts %q, want the data at logging time", texts)
+	}
+}
+
+func TestNotifierSendsFatalBeforeExit(t *testing.T) {
+	if url := os.Getenv("GOLOGGER_TEST_ALERT_URL"); url != "" {
+		notifier, err := New(Config{URL: url, BatchInterval: time.Hour})
+		if err != nil {
+			panic(err)
+		}
+		goLogger.AddHook(notifier.Hook)
+		log := goLogger.NewLog("MAIN")
+		log.Fatal("cannot start")
+		return
+	}
+
+	hook := newWebhook(t)
+	cmd := exec.Command(os.Args[0], "-test.run=^TestNotifierSendsFatalBeforeExit$")
+	cmd.Env = append(os.Environ(), "GOLOGGER_TEST_ALERT_URL="+hook.URL)
+	out, err := cmd.CombinedOutput()
+	if exitErr, ok := err.(*exec.ExitError); !ok || exitErr.ExitCode() != 1 {
+		t.Fatalf("got exit error %v, want exit code 1, output:\n%s", err, out)
+	}
+
+	if texts := hook.texts(); len(texts) != 1 || !strings.Contains(texts[0], "[FATAL]") || !strings.Contains(texts[0], "cannot start") {
+		t.Errorf("got payload texts %q, want the fatal alert", texts)
+	}
+}
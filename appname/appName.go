@@ -5,7 +5,12 @@ import (
 	"path/filepath"
 )
 
+// GetAPPName returns the service name from SERVICE_NAME, OTEL_SERVICE_NAME or the executable name.
 func GetAPPName() (app string) {
+	if app = firstEnv("SERVICE_NAME", "OTEL_SERVICE_NAME"); app != "" {
+		return
+	}
+
 	app = "APP_Name"
 	exe, err := os.Executable()
 	if err == nil {
@@ -0,0 +1,97 @@
+package appname
+
+import (
+	"os"
+	"runtime/debug"
+)
+
+// ServiceInfo describes the running service.
+type ServiceInfo struct {
+	Name         string
+	Version      string
+	Commit       string
+	CommitTime   string
+	Modified     bool
+	GoVersion    string
+	Environment  string
+	Hostname     string
+	PodName      string
+	PodNamespace string
+	NodeName     string
+	PID          int
+}
+
+// GetServiceInfo resolves the service metadata from the environment, the build info and the host.
+//
+// The name is read from SERVICE_NAME, OTEL_SERVICE_NAME or the executable name, the version from
+// SERVICE_VERSION or the main module version, the environment from SERVICE_ENV, ENVIRONMENT or APP_ENV
+// and the Kubernetes metadata from the POD_NAME, POD_NAMESPACE and NODE_NAME downward API variables.
+func GetServiceInfo() ServiceInfo {
+	info := ServiceInfo{
+		Name:         GetAPPName(),
+		Version:      os.Getenv("SERVICE_VERSION"),
+		Environment:  firstEnv("SERVICE_ENV", "ENVIRONMENT", "APP_ENV"),
+		PodName:      os.Getenv("POD_NAME"),
+		PodNamespace: os.Getenv("POD_NAMESPACE"),
+		NodeName:     os.Getenv("NODE_NAME"),
+		PID:          os.Getpid(),
+	}
+	info.Hostname, _ = os.Hostname()
+
+	if buildInfo, ok := debug.ReadBuildInfo(); ok {
+		info.GoVersion = buildInfo.GoVersion
+		if info.Version == "" && buildInfo.Main.Version != "(devel)" {
+			info.Version = buildInfo.Main.Version
+		}
+		for _, setting := range buildInfo.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				info.Commit = setting.Value
+			case "vcs.time":
+				info.CommitTime = setting.Value
+			case "vcs.modified":
+				info.Modified = setting.Value == "true"
+			}
+		}
+	}
+
+	return info
+}
+
+// Fields returns the non empty metadata, as logged in the "service" section.
+func (info ServiceInfo) Fields() map[string]interface{} {
+	fields := map[string]interface{}{
+		"name": info.Name,
+		"pid":  info.PID,
+	}
+
+	for key, value := range map[string]string{
+		"version":       info.Version,
+		"commit":        info.Commit,
+		"commit_time":   info.CommitTime,
+		"go_version":    info.GoVersion,
+		"environment":   info.Environment,
+		"hostname":      info.Hostname,
+		"pod_name":      info.PodName,
+		"pod_namespace": info.PodNamespace,
+		"node_name":     info.NodeName,
+	} {
+		if value != "" {
+			fields[key] = value
+		}
+	}
+	if info.Modified {
+		fields["modified"] = true
+	}
+
+	return fields
+}
+
+func firstEnv(keys ...string) string {
+	for _, key := range keys {
+		if value := os.Getenv(key); value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -152,13 +152,36 @@ func (l logger) Trace(ctx context.Context, begin time.Time, fc func() (string, i
 	logger.SetTrackerID(trackerID)
 	logger.SetTimerStart(begin)
 
-	if l.LogLevel <= lg.Silent {
-		return
+	queryErr := err
+	if errors.Is(err, ErrRecordNotFound) && l.IgnoreRecordNotFoundError {
+		queryErr = nil
 	}
 
 	elapsed := time.Since(begin)
+	failed := queryErr != nil && l.LogLevel >= lg.Error
+	slow := elapsed > l.SlowThreshold && l.SlowThreshold != 0 && l.LogLevel >= lg.Warn
+	if l.LogLevel <= lg.Silent || (!failed && !slow && l.LogLevel != lg.Info) {
+		// not logged at this LogLevel, the listeners (e.g. metrics) still measure the query
+		if goLogger.Listening() {
+			sql, rows := fc()
+			logger.Notify("query_info", goLoggerDB.DatabaseLog(map[string]interface{}{
+				"rows":  rows,
+				"query": sql,
+			}), queryErr)
+		}
+		return
+	}
+
+	// the span only exists for the queries which are logged
+	spanCtx, span := goLogger.StartSpanAt(ctx, "gorm_query", begin)
+	logger.SetContext(spanCtx)
+	defer span.End()
+	if failed {
+		span.SetError(err)
+	}
+
 	switch {
-	case err != nil && l.LogLevel >= lg.Error && (!errors.Is(err, ErrRecordNotFound) || !l.IgnoreRecordNotFoundError):
+	case failed:
 		sql, rows := fc()
 		if rows == -1 {
 			logger.ErrorWithData(
@@ -179,7 +202,7 @@ func (l logger) Trace(ctx context.Context, begin time.Time, fc func() (string, i
 				err,
 			)
 		}
-	case elapsed > l.SlowThreshold && l.SlowThreshold != 0 && l.LogLevel >= lg.Warn:
+	case slow:
 		sql, rows := fc()
 		if rows == -1 {
 			logger.WarnWithData(
@@ -0,0 +1,37 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	goLogger "github.com/pobyzaarif/go-logger/logger"
+	lg "gorm.io/gorm/logger"
+)
+
+func TestTraceLogsOnlyAtLogLevel(t *testing.T) {
+	observer := goLogger.ReplaceGlobalsForTest(t)
+	goLogger.SetSpanLogging(true)
+
+	gormLogger := New(log.New(os.Stdout, "", 0), Config{LogLevel: lg.Error})
+	query := func() (string, int64) { return "SELECT 1", 1 }
+	ctx := context.WithValue(context.Background(), "tracker_id", "abc")
+
+	gormLogger.Trace(ctx, time.Now(), query, nil)
+	if n := observer.Len(); n != 0 {
+		t.Fatalf("got %d entries for a successful query at error level, want 0", n)
+	}
+
+	gormLogger.Trace(ctx, time.Now(), query, errors.New("syntax error"))
+	queries := observer.FilterTag("GORM_QUERY").All()
+	if len(queries) != 1 || queries[0].TrackerID != "abc" || queries[0].Fields["span_id"] == nil {
+		t.Fatalf("got %v, want one failed query with its span", queries)
+	}
+	spans := observer.FilterTag("SPAN").All()
+	if len(spans) != 1 || spans[0].Level != "ERROR" || spans[0].Fields["span_id"] != queries[0].Fields["span_id"] {
+		t.Errorf("got spans %v, want the failed query span", spans)
+	}
+}
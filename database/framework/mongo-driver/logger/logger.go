@@ -2,28 +2,65 @@ package logger
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
+	"time"
 
 	goLoggerDB "github.com/pobyzaarif/go-logger/database"
 	goLogger "github.com/pobyzaarif/go-logger/logger"
 	"go.mongodb.org/mongo-driver/event"
 )
 
+// Monitor logs each command once it succeeded or failed, with its duration.
 func Monitor() *event.CommandMonitor {
+	// commands started and not finished yet, by connection and request ID
+	var commands sync.Map
+
 	return &event.CommandMonitor{
 		Started: func(ctx context.Context, evt *event.CommandStartedEvent) {
-			ctxTrackerID := ctx.Value("tracker_id")
-			trackerID := ""
-			if ctxTrackerID != nil {
-				trackerID = fmt.Sprintf("%v", ctxTrackerID)
-			}
-
-			logger := goLogger.NewLog("MONGO_QUERY")
-			logger.SetTrackerID(trackerID)
-
-			logger.InfoWithData("query_info", goLoggerDB.DatabaseLog(map[string]interface{}{
-				"query": evt.Command.String(),
-			}))
+			commands.Store(commandKey(evt.ConnectionID, evt.RequestID), evt.Command.String())
+		},
+		Succeeded: func(ctx context.Context, evt *event.CommandSucceededEvent) {
+			query, _ := commands.LoadAndDelete(commandKey(evt.ConnectionID, evt.RequestID))
+			logCommand(ctx, evt.CommandFinishedEvent, query, nil)
+		},
+		Failed: func(ctx context.Context, evt *event.CommandFailedEvent) {
+			query, _ := commands.LoadAndDelete(commandKey(evt.ConnectionID, evt.RequestID))
+			logCommand(ctx, evt.CommandFinishedEvent, query, errors.New(evt.Failure))
 		},
 	}
 }
+
+func logCommand(ctx context.Context, evt event.CommandFinishedEvent, query interface{}, err error) {
+	ctxTrackerID := ctx.Value("tracker_id")
+	trackerID := ""
+	if ctxTrackerID != nil {
+		trackerID = fmt.Sprintf("%v", ctxTrackerID)
+	}
+
+	logger := goLogger.NewLog("MONGO_QUERY")
+	logger.SetTrackerID(trackerID)
+	start := time.Now().Add(-evt.Duration)
+	spanCtx, span := goLogger.StartSpanAt(ctx, evt.CommandName, start)
+	logger.SetContext(spanCtx)
+	logger.SetTimerStart(start)
+	defer span.End()
+	if err != nil {
+		span.SetError(err)
+	}
+
+	data := goLoggerDB.DatabaseLog(map[string]interface{}{
+		"operation": evt.CommandName,
+		"query":     query,
+	})
+	if err != nil {
+		logger.ErrorWithData("query_error", data, err)
+		return
+	}
+	logger.InfoWithData("query_info", data)
+}
+
+func commandKey(connectionID string, requestID int64) string {
+	return fmt.Sprintf("%s/%d", connectionID, requestID)
+}
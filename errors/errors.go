@@ -0,0 +1,150 @@
+// Package errors provides an error type carrying a code, an HTTP status, a user facing message,
+// internal details and a stack trace, which go-logger expands into the "error_fields" section.
+package errors
+
+import (
+	"errors"
+	"net/http"
+	"runtime"
+)
+
+// Error is an error carrying log context.
+type Error struct {
+	// Code identifies the error, e.g. "ORDER_NOT_FOUND".
+	Code string
+	// HTTPStatus is the status responded for this error.
+	HTTPStatus int
+	// Message is safe to show to the user.
+	Message string
+	// Details are internal values logged but never shown to the user.
+	Details map[string]interface{}
+	// Err is the cause.
+	Err error
+
+	stack []uintptr
+}
+
+// New returns an Error, recording the stack of the caller.
+func New(code string, httpStatus int, message string) *Error {
+	return newError(nil, code, httpStatus, message)
+}
+
+// Wrap returns an Error caused by err, recording the stack of the caller.
+func Wrap(err error, code string, httpStatus int, message string) *Error {
+	return newError(err, code, httpStatus, message)
+}
+
+func newError(err error, code string, httpStatus int, message string) *Error {
+	pcs := make([]uintptr, 32)
+	return &Error{
+		Code:       code,
+		HTTPStatus: httpStatus,
+		Message:    message,
+		Err:        err,
+		stack:      pcs[:runtime.Callers(3, pcs)],
+	}
+}
+
+// WithDetail returns a copy of e with key set in its details.
+func (e *Error) WithDetail(key string, value interface{}) *Error {
+	return e.WithDetails(map[string]interface{}{key: value})
+}
+
+// WithDetails returns a copy of e with details merged into its details.
+func (e *Error) WithDetails(details map[string]interface{}) *Error {
+	copied := *e
+	copied.Details = make(map[string]interface{}, len(e.Details)+len(details))
+	for k, v := range e.Details {
+		copied.Details[k] = v
+	}
+	for k, v := range details {
+		copied.Details[k] = v
+	}
+	return &copied
+}
+
+func (e *Error) Error() string {
+	msg := e.Message
+	if e.Code != "" {
+		msg = e.Code + ": " + msg
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is an *Error with the same code.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code != "" && t.Code == e.Code
+}
+
+// StackTrace returns the program counters recorded when the error was created.
+func (e *Error) StackTrace() []uintptr {
+	return e.stack
+}
+
+// LogFields returns the fields logged in the "error_fields" section.
+func (e *Error) LogFields() map[string]interface{} {
+	fields := map[string]interface{}{
+		"code":         e.Code,
+		"http_status":  e.HTTPStatus,
+		"user_message": e.Message,
+	}
+	if len(e.Details) > 0 {
+		fields["details"] = e.Details
+	}
+	return fields
+}
+
+// HTTPStatus returns the status of the first *Error in the chain of err, or 500.
+func HTTPStatus(err error) int {
+	var e *Error
+	if errors.As(err, &e) && e.HTTPStatus != 0 {
+		return e.HTTPStatus
+	}
+	return http.StatusInternalServerError
+}
+
+// Code returns the code of the first *Error in the chain of err, or "".
+func Code(err error) string {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return ""
+}
+
+// UserMessage returns the user facing message of the first *Error in the chain of err, or the status text of HTTPStatus.
+func UserMessage(err error) string {
+	var e *Error
+	if errors.As(err, &e) && e.Message != "" {
+		return e.Message
+	}
+	return http.StatusText(HTTPStatus(err))
+}
+
+// Is reports whether any error in err's chain matches target, see errors.Is.
+func Is(err, target error) bool {
+	return errors.Is(err, target)
+}
+
+// As finds the first error in err's chain that matches target, see errors.As.
+func As(err error, target interface{}) bool {
+	return errors.As(err, target)
+}
+
+// Unwrap returns the result of calling the Unwrap method on err, see errors.Unwrap.
+func Unwrap(err error) error {
+	return errors.Unwrap(err)
+}
+
+// Join returns an error that wraps the given errors, see errors.Join.
+func Join(errs ...error) error {
+	return errors.Join(errs...)
+}
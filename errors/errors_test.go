@@ -0,0 +1,109 @@
+package errors_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	goLoggerErrors "github.com/pobyzaarif/go-logger/errors"
+	goLogger "github.com/pobyzaarif/go-logger/logger"
+)
+
+var errOrderNotFound = goLoggerErrors.New("ORDER_NOT_FOUND", http.StatusNotFound, "order not found")
+
+func TestWrapIsByCode(t *testing.T) {
+	cause := errors.New("no rows")
+	err := fmt.Errorf("get order: %w", goLoggerErrors.Wrap(cause, "ORDER_NOT_FOUND", http.StatusNotFound, "order 12 not found"))
+
+	if !goLoggerErrors.Is(err, errOrderNotFound) {
+		t.Error("wrapped error does not match the error of the same code")
+	}
+	if !goLoggerErrors.Is(err, cause) {
+		t.Error("wrapped error does not match its cause")
+	}
+	if goLoggerErrors.Is(err, goLoggerErrors.New("ORDER_CANCELLED", http.StatusConflict, "order cancelled")) {
+		t.Error("wrapped error matches an error of another code")
+	}
+	if goLoggerErrors.Is(goLoggerErrors.New("", 0, "a"), goLoggerErrors.New("", 0, "a")) {
+		t.Error("errors without code match each other")
+	}
+	if got, want := err.Error(), "get order: ORDER_NOT_FOUND: order 12 not found: no rows"; got != want {
+		t.Errorf("got message %q, want %q", got, want)
+	}
+	if len(errOrderNotFound.StackTrace()) == 0 {
+		t.Error("got no stack trace")
+	}
+}
+
+func TestDefaults(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		status      int
+		userMessage string
+		code        string
+	}{
+		{"plain error", errors.New("boom"), http.StatusInternalServerError, "Internal Server Error", ""},
+		{"wrapped", fmt.Errorf("get: %w", errOrderNotFound), http.StatusNotFound, "order not found", "ORDER_NOT_FOUND"},
+		{"no status", goLoggerErrors.New("INVALID", 0, "invalid input"), http.StatusInternalServerError, "invalid input", "INVALID"},
+		{"no message", goLoggerErrors.New("CONFLICT", http.StatusConflict, ""), http.StatusConflict, "Conflict", "CONFLICT"},
+		{"nil", nil, http.StatusInternalServerError, "Internal Server Error", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := goLoggerErrors.HTTPStatus(tt.err); got != tt.status {
+				t.Errorf("got HTTPStatus %d, want %d", got, tt.status)
+			}
+			if got := goLoggerErrors.UserMessage(tt.err); got != tt.userMessage {
+				t.Errorf("got UserMessage %q, want %q", got, tt.userMessage)
+			}
+			if got := goLoggerErrors.Code(tt.err); got != tt.code {
+				t.Errorf("got Code %q, want %q", got, tt.code)
+			}
+		})
+	}
+}
+
+func TestWithDetailsCopies(t *testing.T) {
+	withID := errOrderNotFound.WithDetail("order_id", 12)
+	withUser := withID.WithDetails(map[string]interface{}{"user_id": 3})
+
+	if errOrderNotFound.Details != nil || len(withID.Details) != 1 || len(withUser.Details) != 2 {
+		t.Errorf("got details %v, %v and %v, want each copy to keep its own", errOrderNotFound.Details, withID.Details, withUser.Details)
+	}
+}
+
+func TestErrorFieldsAreLogged(t *testing.T) {
+	observer := goLogger.ReplaceGlobalsForTest(t)
+	config := goLogger.DefaultConfig
+	config.Redaction.Keys = []string{"card_number"}
+	if err := goLogger.Configure(config); err != nil {
+		t.Fatal(err)
+	}
+	goLogger.SetOutput(observer)
+
+	err := errOrderNotFound.WithDetails(map[string]interface{}{"order_id": 12, "card_number": "4111"})
+	log := goLogger.NewLog("TEST")
+	log.Error("failed", fmt.Errorf("get order: %w", err))
+	log.ErrorWithData("failed with data", map[string]interface{}{"id": 12}, err)
+	log.Error("plain", errors.New("boom"))
+
+	for _, message := range []string{"failed", "failed with data"} {
+		entries := observer.FilterMessage(message).All()
+		if len(entries) != 1 {
+			t.Fatalf("got %d %q entries, want 1", len(entries), message)
+		}
+		fields, _ := entries[0].Fields["error_fields"].(map[string]interface{})
+		if fields["code"] != "ORDER_NOT_FOUND" || fields["http_status"] != float64(http.StatusNotFound) || fields["user_message"] != "order not found" {
+			t.Errorf("%s: got error_fields %v", message, fields)
+		}
+		details, _ := fields["details"].(map[string]interface{})
+		if details["order_id"] != float64(12) || details["card_number"] != "**hidden**" {
+			t.Errorf("%s: got details %v, want order_id and a hidden card_number", message, details)
+		}
+	}
+	if _, ok := observer.FilterMessage("plain").All()[0].Fields["error_fields"]; ok {
+		t.Error("got error_fields for an error without log fields")
+	}
+}
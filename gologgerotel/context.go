@@ -0,0 +1,29 @@
+// Package gologgerotel bridges go-logger with OpenTelemetry: it stamps the trace context of the active OTel span
+// on log lines and exports log lines to an OTLP collector over gRPC or HTTP.
+package gologgerotel
+
+import (
+	"context"
+
+	goLogger "github.com/pobyzaarif/go-logger/logger"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TraceContext is a goLogger.ContextExtractor returning the trace_id, span_id and trace_flags of the OTel span of ctx.
+func TraceContext(ctx context.Context) map[string]interface{} {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.IsValid() {
+		return nil
+	}
+
+	return map[string]interface{}{
+		"trace_id":    spanContext.TraceID().String(),
+		"span_id":     spanContext.SpanID().String(),
+		"trace_flags": spanContext.TraceFlags().String(),
+	}
+}
+
+// Install registers TraceContext, the log lines of a logger set with SetContext then carry the OTel trace context.
+func Install() (remove func()) {
+	return goLogger.AddContextExtractor(TraceContext)
+}
@@ -0,0 +1,473 @@
+package gologgerotel
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	goLoggerAppName "github.com/pobyzaarif/go-logger/appname"
+	goLogger "github.com/pobyzaarif/go-logger/logger"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+)
+
+type (
+	Protocol int
+
+	// Config defines the config for Exporter.
+	Config struct {
+		// Protocol is the OTLP transport.
+		// Optional. Default value GRPCProtocol.
+		Protocol Protocol
+
+		// Endpoint is the collector "host:port" for GRPCProtocol, or the logs url for HTTPProtocol.
+		// Optional. Default value "localhost:4317" for GRPCProtocol, "http://localhost:4318/v1/logs" for HTTPProtocol.
+		Endpoint string
+
+		// Insecure disables TLS for GRPCProtocol, HTTPProtocol follows the scheme of Endpoint.
+		// Optional. Default value false.
+		Insecure bool
+
+		// Headers are sent with every export request, e.g. an authorization token.
+		// Optional. Default value nil.
+		Headers map[string]string
+
+		// Timeout is the timeout of an export request.
+		// Optional. Default value 10 seconds.
+		Timeout time.Duration
+
+		// BatchSize is the maximum number of log records sent in one request.
+		// Optional. Default value 512.
+		BatchSize int
+
+		// BatchInterval is how long log records are gathered before being sent.
+		// Optional. Default value 1 second.
+		BatchInterval time.Duration
+
+		// QueueSize is the number of log records waiting to be sent, new log records are dropped beyond it.
+		// Optional. Default value 2048.
+		QueueSize int
+
+		// Resource are the resource attributes of the log records.
+		// Optional. Default value the OTel semantic conventions of appname.GetServiceInfo (service.name, host.name, ...).
+		Resource map[string]string
+	}
+
+	// Exporter is an io.Writer sending the JSON log lines of go-logger to an OTLP collector,
+	// e.g. goLogger.SetOutput(io.MultiWriter(os.Stdout, exporter)).
+	Exporter struct {
+		config    Config
+		sender    sender
+		resource  *resourcepb.Resource
+		queue     chan *logspb.LogRecord
+		done      chan struct{}
+		closeOnce sync.Once
+		wg        sync.WaitGroup
+	}
+
+	sender interface {
+		send(ctx context.Context, request *collogspb.ExportLogsServiceRequest) error
+		close() error
+	}
+
+	grpcSender struct {
+		conn   *grpc.ClientConn
+		client collogspb.LogsServiceClient
+	}
+
+	httpSender struct {
+		url     string
+		headers map[string]string
+		client  *http.Client
+	}
+)
+
+// Protocol possible values
+const (
+	GRPCProtocol Protocol = iota
+	HTTPProtocol
+)
+
+// tag of the entries logged by the exporter itself, never exported
+const otelTag = "GOLOGGER_OTEL"
+
+// scope of the exported log records
+const scopeName = "github.com/pobyzaarif/go-logger"
+
+var (
+	// DefaultConfig is the default Exporter config.
+	DefaultConfig = Config{
+		Protocol:      GRPCProtocol,
+		Timeout:       10 * time.Second,
+		BatchSize:     512,
+		BatchInterval: time.Second,
+		QueueSize:     2048,
+	}
+
+	logger = goLogger.NewLog(otelTag)
+)
+
+// NewExporter creates an Exporter and starts its sending goroutine, Close flushes and stops it.
+func NewExporter(config Config) (*Exporter, error) {
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultConfig.Timeout
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = DefaultConfig.BatchSize
+	}
+	if config.BatchInterval <= 0 {
+		config.BatchInterval = DefaultConfig.BatchInterval
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = DefaultConfig.QueueSize
+	}
+	if config.Resource == nil {
+		config.Resource = serviceResource(goLoggerAppName.GetServiceInfo())
+	}
+
+	var s sender
+	switch config.Protocol {
+	case GRPCProtocol:
+		if config.Endpoint == "" {
+			config.Endpoint = "localhost:4317"
+		}
+		creds := credentials.NewTLS(&tls.Config{})
+		if config.Insecure {
+			creds = insecure.NewCredentials()
+		}
+		conn, err := grpc.NewClient(config.Endpoint, grpc.WithTransportCredentials(creds))
+		if err != nil {
+			return nil, fmt.Errorf("gologgerotel: %w", err)
+		}
+		s = &grpcSender{conn: conn, client: collogspb.NewLogsServiceClient(conn)}
+	case HTTPProtocol:
+		if config.Endpoint == "" {
+			config.Endpoint = "http://localhost:4318/v1/logs"
+		}
+		s = &httpSender{url: config.Endpoint, headers: config.Headers, client: &http.Client{}}
+	default:
+		return nil, errors.New("gologgerotel: unknown protocol")
+	}
+
+	e := &Exporter{
+		config:   config,
+		sender:   s,
+		resource: &resourcepb.Resource{Attributes: stringAttributes(config.Resource)},
+		queue:    make(chan *logspb.LogRecord, config.QueueSize),
+		done:     make(chan struct{}),
+	}
+
+	e.wg.Add(1)
+	go e.run()
+
+	return e, nil
+}
+
+// Write queues the log line p, it never blocks: log records are dropped when the queue is full.
+// Lines which are not JSON, e.g. of the text format, are exported with the whole line as body.
+func (e *Exporter) Write(p []byte) (int, error) {
+	for _, line := range bytes.Split(p, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+
+		record := newLogRecord(line)
+		if record == nil {
+			continue
+		}
+
+		select {
+		case e.queue <- record:
+		default:
+			// queue is full, drop rather than block the logging path
+		}
+	}
+
+	return len(p), nil
+}
+
+// Close sends the queued log records and stops the exporter.
+func (e *Exporter) Close() error {
+	e.closeOnce.Do(func() {
+		close(e.done)
+	})
+	e.wg.Wait()
+	return e.sender.close()
+}
+
+func (e *Exporter) run() {
+	defer e.wg.Done()
+
+	ticker := time.NewTicker(e.config.BatchInterval)
+	defer ticker.Stop()
+
+	var pending []*logspb.LogRecord
+	for {
+		select {
+		case record := <-e.queue:
+			pending = append(pending, record)
+			if len(pending) >= e.config.BatchSize {
+				e.export(pending)
+				pending = nil
+			}
+		case <-ticker.C:
+			if len(pending) > 0 {
+				e.export(pending)
+				pending = nil
+			}
+		case <-e.done:
+			for {
+				select {
+				case record := <-e.queue:
+					pending = append(pending, record)
+					continue
+				default:
+				}
+				break
+			}
+			for len(pending) > 0 {
+				size := e.config.BatchSize
+				if size > len(pending) {
+					size = len(pending)
+				}
+				e.export(pending[:size])
+				pending = pending[size:]
+			}
+			return
+		}
+	}
+}
+
+func (e *Exporter) export(records []*logspb.LogRecord) {
+	request := &collogspb.ExportLogsServiceRequest{
+		ResourceLogs: []*logspb.ResourceLogs{{
+			Resource: e.resource,
+			ScopeLogs: []*logspb.ScopeLogs{{
+				Scope:      &commonpb.InstrumentationScope{Name: scopeName},
+				LogRecords: records,
+			}},
+		}},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), e.config.Timeout)
+	defer cancel()
+	if len(e.config.Headers) > 0 {
+		ctx = metadata.NewOutgoingContext(ctx, metadata.New(e.config.Headers))
+	}
+
+	if err := e.sender.send(ctx, request); err != nil {
+		logger.WarnWithDataAndError("otlp export failed", map[string]interface{}{"log_records": len(records)}, err)
+	}
+}
+
+func (s *grpcSender) send(ctx context.Context, request *collogspb.ExportLogsServiceRequest) error {
+	_, err := s.client.Export(ctx, request)
+	return err
+}
+
+func (s *grpcSender) close() error {
+	return s.conn.Close()
+}
+
+func (s *httpSender) send(ctx context.Context, request *collogspb.ExportLogsServiceRequest) error {
+	body, err := proto.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	for k, v := range s.headers {
+		req.Header.Set(k, v)
+	}
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, res.Body)
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("gologgerotel: collector responded %s", res.Status)
+	}
+	return nil
+}
+
+func (s *httpSender) close() error {
+	return nil
+}
+
+// fields of a log line mapped to the log record itself or to the resource, not exported as attributes
+var recordFields = map[string]bool{
+	"time":         true,
+	"level":        true,
+	"message":      true,
+	"trace_id":     true,
+	"span_id":      true,
+	"trace_flags":  true,
+	"service":      true,
+	"service_name": true,
+}
+
+// newLogRecord converts a log line, nil for the lines logged by the exporter itself.
+func newLogRecord(line []byte) *logspb.LogRecord {
+	now := uint64(time.Now().UnixNano())
+	record := &logspb.LogRecord{ObservedTimeUnixNano: now}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(line, &fields); err != nil {
+		record.TimeUnixNano = now
+		record.Body = stringValue(string(line))
+		return record
+	}
+	if fields["tag"] == otelTag {
+		return nil
+	}
+
+	record.TimeUnixNano = now
+	if t, ok := goLogger.ParseTime(fields["time"]); ok {
+		record.TimeUnixNano = uint64(t.UnixNano())
+	}
+
+	level, _ := fields["level"].(string)
+	record.SeverityText = level
+	record.SeverityNumber = severity(level)
+	record.Body = anyValue(fields["message"])
+
+	if traceID, err := hex.DecodeString(fmt.Sprint(fields["trace_id"])); err == nil && len(traceID) == 16 {
+		record.TraceId = traceID
+		if spanID, err := hex.DecodeString(fmt.Sprint(fields["span_id"])); err == nil && len(spanID) == 8 {
+			record.SpanId = spanID
+		}
+		if flags, err := strconv.ParseUint(fmt.Sprint(fields["trace_flags"]), 16, 8); err == nil {
+			record.Flags = uint32(flags)
+		}
+	}
+
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		if !recordFields[k] {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		record.Attributes = append(record.Attributes, &commonpb.KeyValue{Key: k, Value: anyValue(fields[k])})
+	}
+
+	return record
+}
+
+func severity(level string) logspb.SeverityNumber {
+	switch level {
+	case "INFO":
+		return logspb.SeverityNumber_SEVERITY_NUMBER_INFO
+	case "WARN":
+		return logspb.SeverityNumber_SEVERITY_NUMBER_WARN
+	case "ERROR":
+		return logspb.SeverityNumber_SEVERITY_NUMBER_ERROR
+	case "FATAL":
+		return logspb.SeverityNumber_SEVERITY_NUMBER_FATAL
+	}
+	return logspb.SeverityNumber_SEVERITY_NUMBER_UNSPECIFIED
+}
+
+// anyValue converts a decoded JSON value, objects become key value lists.
+func anyValue(value interface{}) *commonpb.AnyValue {
+	switch v := value.(type) {
+	case nil:
+		return &commonpb.AnyValue{}
+	case string:
+		return stringValue(v)
+	case bool:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_BoolValue{BoolValue: v}}
+	case float64:
+		if v == float64(int64(v)) {
+			return &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: int64(v)}}
+		}
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_DoubleValue{DoubleValue: v}}
+	case []interface{}:
+		values := make([]*commonpb.AnyValue, len(v))
+		for i, item := range v {
+			values[i] = anyValue(item)
+		}
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_ArrayValue{ArrayValue: &commonpb.ArrayValue{Values: values}}}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		values := make([]*commonpb.KeyValue, len(keys))
+		for i, k := range keys {
+			values[i] = &commonpb.KeyValue{Key: k, Value: anyValue(v[k])}
+		}
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_KvlistValue{KvlistValue: &commonpb.KeyValueList{Values: values}}}
+	}
+	return stringValue(fmt.Sprint(value))
+}
+
+func stringValue(s string) *commonpb.AnyValue {
+	return &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: s}}
+}
+
+func stringAttributes(attributes map[string]string) []*commonpb.KeyValue {
+	keys := make([]string, 0, len(attributes))
+	for k := range attributes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	values := make([]*commonpb.KeyValue, len(keys))
+	for i, k := range keys {
+		values[i] = &commonpb.KeyValue{Key: k, Value: stringValue(attributes[k])}
+	}
+	return values
+}
+
+// serviceResource maps the service info to the OTel resource semantic conventions, empty values are left out.
+func serviceResource(info goLoggerAppName.ServiceInfo) map[string]string {
+	resource := map[string]string{
+		"service.name":           info.Name,
+		"service.version":        info.Version,
+		"deployment.environment": info.Environment,
+		"host.name":              info.Hostname,
+		"k8s.pod.name":           info.PodName,
+		"k8s.namespace.name":     info.PodNamespace,
+		"k8s.node.name":          info.NodeName,
+		"process.pid":            strconv.Itoa(info.PID),
+		"telemetry.sdk.language": "go",
+	}
+	if info.Name == "" {
+		resource["service.name"] = os.Args[0]
+	}
+	for k, v := range resource {
+		if v == "" {
+			delete(resource, k)
+		}
+	}
+	return resource
+}
@@ -0,0 +1,98 @@
+package gologgerotel
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	goLogger "github.com/pobyzaarif/go-logger/logger"
+	"go.opentelemetry.io/otel/trace"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+)
+
+func attribute(attributes []*commonpb.KeyValue, key string) *commonpb.AnyValue {
+	for _, kv := range attributes {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return nil
+}
+
+func TestExporterSendsToReceiver(t *testing.T) {
+	traceID, _ := trace.TraceIDFromHex("0af7651916cd43dd8448eb211c80319c")
+	spanID, _ := trace.SpanIDFromHex("b7ad6b7169203331")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	}))
+
+	receiver, err := NewReceiver()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer receiver.Close()
+
+	for name, config := range map[string]Config{
+		"grpc": {Protocol: GRPCProtocol, Endpoint: receiver.GRPCEndpoint, Insecure: true},
+		"http": {Protocol: HTTPProtocol, Endpoint: receiver.HTTPEndpoint},
+	} {
+		t.Run(name, func(t *testing.T) {
+			received := len(receiver.LogRecords())
+			observer := goLogger.ReplaceGlobalsForTest(t)
+			defer Install()()
+
+			config.BatchInterval = time.Hour
+			config.Resource = map[string]string{"service.name": "orders"}
+			exporter, err := NewExporter(config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			goLogger.SetOutput(io.MultiWriter(observer, exporter))
+
+			log := goLogger.NewLog("TEST")
+			log.SetContext(ctx)
+			log.Info("created")
+			log.Error("failed", errors.New("boom"))
+			if err := exporter.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			requests := receiver.Requests()
+			resource := requests[len(requests)-1].ResourceLogs[0].Resource
+			if v := attribute(resource.Attributes, "service.name"); v.GetStringValue() != "orders" {
+				t.Errorf("got service.name %v, want orders", v)
+			}
+
+			records := receiver.LogRecords()[received:]
+			if len(records) != 2 {
+				t.Fatalf("got %d log records, want 2", len(records))
+			}
+			if records[0].Body.GetStringValue() != "created" || records[0].SeverityNumber != logspb.SeverityNumber_SEVERITY_NUMBER_INFO {
+				t.Errorf("got record %v, want the created info line", records[0])
+			}
+			if records[1].SeverityNumber != logspb.SeverityNumber_SEVERITY_NUMBER_ERROR || attribute(records[1].Attributes, "error").GetStringValue() != "boom" {
+				t.Errorf("got record %v, want the failed error line", records[1])
+			}
+			for _, record := range records {
+				if hex.EncodeToString(record.TraceId) != traceID.String() || hex.EncodeToString(record.SpanId) != spanID.String() {
+					t.Errorf("got trace %x span %x, want the OTel span context", record.TraceId, record.SpanId)
+				}
+				if attribute(record.Attributes, "tag").GetStringValue() != "TEST" {
+					t.Errorf("got attributes %v, want the tag", record.Attributes)
+				}
+			}
+		})
+	}
+}
+
+func TestTraceContextWithoutSpan(t *testing.T) {
+	if fields := TraceContext(context.Background()); fields != nil {
+		t.Errorf("got %v for a context without span, want nil", fields)
+	}
+}
@@ -0,0 +1,120 @@
+package gologgerotel
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// Receiver is an OTLP logs receiver stub listening on local gRPC and HTTP ports, to test an Exporter without a collector.
+type Receiver struct {
+	// GRPCEndpoint is the Endpoint of a GRPCProtocol exporter, "127.0.0.1:<port>" (insecure).
+	GRPCEndpoint string
+	// HTTPEndpoint is the Endpoint of an HTTPProtocol exporter, "http://127.0.0.1:<port>/v1/logs".
+	HTTPEndpoint string
+
+	grpcServer *grpc.Server
+	httpServer *http.Server
+	requests   []*collogspb.ExportLogsServiceRequest
+	mutex      sync.Mutex
+}
+
+type receiverService struct {
+	collogspb.UnimplementedLogsServiceServer
+	receiver *Receiver
+}
+
+// NewReceiver starts a Receiver, Close stops it.
+func NewReceiver() (*Receiver, error) {
+	grpcListener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	httpListener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		grpcListener.Close()
+		return nil, err
+	}
+
+	r := &Receiver{
+		GRPCEndpoint: grpcListener.Addr().String(),
+		HTTPEndpoint: fmt.Sprintf("http://%s/v1/logs", httpListener.Addr()),
+		grpcServer:   grpc.NewServer(),
+	}
+	collogspb.RegisterLogsServiceServer(r.grpcServer, &receiverService{receiver: r})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/logs", r.serveHTTP)
+	r.httpServer = &http.Server{Handler: mux}
+
+	go func() { _ = r.grpcServer.Serve(grpcListener) }()
+	go func() { _ = r.httpServer.Serve(httpListener) }()
+
+	return r, nil
+}
+
+func (s *receiverService) Export(_ context.Context, request *collogspb.ExportLogsServiceRequest) (*collogspb.ExportLogsServiceResponse, error) {
+	s.receiver.record(request)
+	return &collogspb.ExportLogsServiceResponse{}, nil
+}
+
+func (r *Receiver) serveHTTP(w http.ResponseWriter, req *http.Request) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	request := &collogspb.ExportLogsServiceRequest{}
+	if err := proto.Unmarshal(body, request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	r.record(request)
+
+	response, _ := proto.Marshal(&collogspb.ExportLogsServiceResponse{})
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	_, _ = w.Write(response)
+}
+
+func (r *Receiver) record(request *collogspb.ExportLogsServiceRequest) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.requests = append(r.requests, request)
+}
+
+// Requests returns the export requests received so far.
+func (r *Receiver) Requests() []*collogspb.ExportLogsServiceRequest {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return append([]*collogspb.ExportLogsServiceRequest{}, r.requests...)
+}
+
+// LogRecords returns the log records received so far, in order.
+func (r *Receiver) LogRecords() []*logspb.LogRecord {
+	var records []*logspb.LogRecord
+	for _, request := range r.Requests() {
+		for _, resourceLogs := range request.ResourceLogs {
+			for _, scopeLogs := range resourceLogs.ScopeLogs {
+				records = append(records, scopeLogs.LogRecords...)
+			}
+		}
+	}
+	return records
+}
+
+// Close stops the receiver.
+func (r *Receiver) Close() error {
+	r.grpcServer.Stop()
+	return r.httpServer.Close()
+}
@@ -0,0 +1,120 @@
+// Package gologgertest provides an in-memory sink and a mock HTTP server to assert on go-logger output in tests.
+package gologgertest
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	echoMiddleware "github.com/labstack/echo/v4/middleware"
+	goLoggerClient "github.com/pobyzaarif/go-logger/http/client"
+	goLogger "github.com/pobyzaarif/go-logger/logger"
+	goLoggerMiddleware "github.com/pobyzaarif/go-logger/rest/framework/echo/v4/middleware"
+)
+
+// Server is an httptest.Server whose go-logger output is captured in Sink.
+type Server struct {
+	*httptest.Server
+	Sink *Sink
+}
+
+var (
+	current      *Sink
+	currentMutex sync.Mutex
+)
+
+// Capture redirects go-logger output to a new Sink until the test ends.
+func Capture(t testing.TB) *Sink {
+	t.Helper()
+
+	sink := &Sink{Observer: goLogger.ReplaceGlobalsForTest(t)}
+
+	currentMutex.Lock()
+	previous := current
+	current = sink
+	currentMutex.Unlock()
+
+	t.Cleanup(func() {
+		currentMutex.Lock()
+		current = previous
+		currentMutex.Unlock()
+	})
+
+	return sink
+}
+
+// NewServer starts an httptest.Server serving handler, with go-logger output captured until the test ends.
+func NewServer(t testing.TB, handler http.Handler) *Server {
+	t.Helper()
+
+	server := &Server{
+		Server: httptest.NewServer(handler),
+		Sink:   Capture(t),
+	}
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// NewEchoServer starts a server running echo with the go-logger middlewares
+// (ServiceRequestTime, ServiceTrackerID, APILogHandler as body dump and Recover), routes are registered by routes.
+func NewEchoServer(t testing.TB, routes func(e *echo.Echo)) *Server {
+	t.Helper()
+
+	e := echo.New()
+	e.HideBanner = true
+	e.HidePort = true
+	e.Use(goLoggerMiddleware.ServiceRequestTime)
+	e.Use(goLoggerMiddleware.ServiceTrackerID)
+	e.Use(echoMiddleware.BodyDump(goLoggerMiddleware.APILogHandler))
+	e.Use(goLoggerMiddleware.Recover())
+	if routes != nil {
+		routes(e)
+	}
+
+	return NewServer(t, e)
+}
+
+// Call sends a request to path on the server through http/client.Call.
+func (s *Server) Call(
+	ctx context.Context,
+	method string,
+	path string,
+	body io.Reader,
+	responseBodyFormat goLoggerClient.ResponseBodyFormat,
+	responseBody interface{}) (int, error) {
+	request, err := http.NewRequestWithContext(ctx, method, s.URL+path, body)
+	if err != nil {
+		return 0, err
+	}
+
+	return goLoggerClient.Call(ctx, request, 5*time.Second, responseBodyFormat, responseBody, nil)
+}
+
+// AssertLogged fails the test when no entry captured by the running Server or Capture matches all matchers.
+func AssertLogged(t testing.TB, matchers ...Matcher) {
+	t.Helper()
+	currentSink(t).AssertLogged(t, matchers...)
+}
+
+// AssertNotLogged fails the test when an entry captured by the running Server or Capture matches all matchers.
+func AssertNotLogged(t testing.TB, matchers ...Matcher) {
+	t.Helper()
+	currentSink(t).AssertNotLogged(t, matchers...)
+}
+
+func currentSink(t testing.TB) *Sink {
+	t.Helper()
+
+	currentMutex.Lock()
+	defer currentMutex.Unlock()
+	if current == nil {
+		t.Fatal("gologgertest: no captured output, call Capture, NewServer or NewEchoServer first")
+	}
+	return current
+}
@@ -0,0 +1,62 @@
+package gologgertest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	goLoggerClient "github.com/pobyzaarif/go-logger/http/client"
+	goLogger "github.com/pobyzaarif/go-logger/logger"
+)
+
+// recorder records the failures of the assertions under test instead of failing the test.
+type recorder struct {
+	testing.TB
+	failures []string
+}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func TestCaptureAndMatchers(t *testing.T) {
+	sink := Capture(t)
+
+	log := goLogger.NewLog("TEST")
+	log.SetTrackerID("abc")
+	log.InfoWithData("created", map[string]interface{}{"id": 1, "user": map[string]interface{}{"name": "alice"}})
+	log.Error("failed", errors.New("boom"))
+
+	AssertLogged(t, Tag("TEST"), Level("info"), Message("created"), TrackerID("abc"))
+	AssertLogged(t, Field("app.user.name", "alice"), Field("data.app.id", 1))
+	AssertLogged(t, Level("ERROR"), Field("error", "boom"), HasField("error_fingerprint"))
+	AssertNotLogged(t, Level("warn"))
+
+	if n := len(sink.Entries(Tag("TEST"))); n != 2 {
+		t.Errorf("got %d entries, want 2", n)
+	}
+
+	r := &recorder{TB: t}
+	sink.AssertLogged(r, Message("deleted"))
+	sink.AssertNotLogged(r, Message("created"))
+	if len(r.failures) != 2 {
+		t.Errorf("got %d failures, want 2: %v", len(r.failures), r.failures)
+	}
+
+	sink.Reset()
+	AssertNotLogged(t, Tag("TEST"))
+}
+
+func TestEchoServerCapturesBothSides(t *testing.T) {
+	server := NewEchoServer(t, nil)
+
+	ctx := context.WithValue(context.Background(), "tracker_id", "abc")
+	if _, err := server.Call(ctx, http.MethodGet, "/missing", nil, goLoggerClient.RawResponseBodyFormat, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	server.Sink.AssertLogged(t, Tag("OUTBOUND_REQUEST"), TrackerID("abc"), Field("net.response_http_code", http.StatusNotFound))
+	server.Sink.AssertLogged(t, Tag("INBOUND_REQUEST"), TrackerID("abc"), Field("net.route", ""))
+}
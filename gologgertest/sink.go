@@ -0,0 +1,177 @@
+package gologgertest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	goLogger "github.com/pobyzaarif/go-logger/logger"
+)
+
+type (
+	// Entry is a decoded go-logger JSON line.
+	Entry map[string]interface{}
+
+	// Matcher reports whether an entry matches.
+	Matcher struct {
+		desc  string
+		match func(Entry) bool
+	}
+
+	// Sink is an in-memory io.Writer collecting go-logger entries.
+	Sink struct {
+		*goLogger.Observer
+	}
+)
+
+func NewSink() *Sink {
+	return &Sink{Observer: goLogger.NewObserver()}
+}
+
+// Entries returns the collected entries matching all matchers.
+func (s *Sink) Entries(matchers ...Matcher) []Entry {
+	entries := []Entry{}
+	for _, observed := range s.All() {
+		entry := Entry(observed.Fields)
+		if matchAll(entry, matchers) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// Reset drops the collected entries.
+func (s *Sink) Reset() {
+	s.TakeAll()
+}
+
+// AssertLogged fails the test when no entry matches all matchers.
+func (s *Sink) AssertLogged(t testing.TB, matchers ...Matcher) {
+	t.Helper()
+	if len(s.Entries(matchers...)) == 0 {
+		t.Errorf("no log entry matches %s, logged:\n%s", describe(matchers), s.summary())
+	}
+}
+
+// AssertNotLogged fails the test when an entry matches all matchers.
+func (s *Sink) AssertNotLogged(t testing.TB, matchers ...Matcher) {
+	t.Helper()
+	if entries := s.Entries(matchers...); len(entries) > 0 {
+		t.Errorf("%d log entries match %s, logged:\n%s", len(entries), describe(matchers), s.summary())
+	}
+}
+
+func (s *Sink) summary() string {
+	var sb strings.Builder
+	for _, entry := range s.Entries() {
+		fmt.Fprintf(&sb, "  [%v] %v %q error=%q\n", entry["level"], entry["tag"], entry["message"], entry["error"])
+	}
+	if sb.Len() == 0 {
+		return "  (nothing)\n"
+	}
+	return sb.String()
+}
+
+// Tag matches entries logged with tag.
+func Tag(tag string) Matcher {
+	return Matcher{
+		desc:  fmt.Sprintf("tag=%s", tag),
+		match: func(e Entry) bool { return e["tag"] == tag },
+	}
+}
+
+// Level matches entries logged at level, case insensitive (e.g. "error").
+func Level(level string) Matcher {
+	return Matcher{
+		desc: fmt.Sprintf("level=%s", level),
+		match: func(e Entry) bool {
+			l, _ := e["level"].(string)
+			return strings.EqualFold(l, level)
+		},
+	}
+}
+
+// Message matches entries logged with message.
+func Message(message string) Matcher {
+	return Matcher{
+		desc:  fmt.Sprintf("message=%q", message),
+		match: func(e Entry) bool { return e["message"] == message },
+	}
+}
+
+// TrackerID matches entries logged with tracker_id.
+func TrackerID(trackerID string) Matcher {
+	return Matcher{
+		desc:  fmt.Sprintf("tracker_id=%s", trackerID),
+		match: func(e Entry) bool { return e["tracker_id"] == trackerID },
+	}
+}
+
+// Field matches entries whose field at a dot separated path equals value.
+// The path is looked up from the entry root, then from its "data" section,
+// so "net.response_http_code" and "data.net.response_http_code" are equivalent.
+func Field(path string, value interface{}) Matcher {
+	expected, _ := json.Marshal(value)
+	return Matcher{
+		desc: fmt.Sprintf("%s=%s", path, expected),
+		match: func(e Entry) bool {
+			actual, ok := e.Field(path)
+			if !ok {
+				return false
+			}
+			b, _ := json.Marshal(actual)
+			return bytes.Equal(b, expected)
+		},
+	}
+}
+
+// HasField matches entries having a field at a dot separated path.
+func HasField(path string) Matcher {
+	return Matcher{
+		desc: fmt.Sprintf("has %s", path),
+		match: func(e Entry) bool {
+			_, ok := e.Field(path)
+			return ok
+		},
+	}
+}
+
+// Field returns the value at a dot separated path, looked up from the root then from "data".
+func (e Entry) Field(path string) (interface{}, bool) {
+	if v, ok := lookup(map[string]interface{}(e), strings.Split(path, ".")); ok {
+		return v, true
+	}
+	data, _ := e["data"].(map[string]interface{})
+	return lookup(data, strings.Split(path, "."))
+}
+
+func lookup(m map[string]interface{}, keys []string) (interface{}, bool) {
+	v, ok := m[keys[0]]
+	if !ok || len(keys) == 1 {
+		return v, ok
+	}
+	next, _ := v.(map[string]interface{})
+	if next == nil {
+		return nil, false
+	}
+	return lookup(next, keys[1:])
+}
+
+func matchAll(entry Entry, matchers []Matcher) bool {
+	for _, matcher := range matchers {
+		if !matcher.match(entry) {
+			return false
+		}
+	}
+	return true
+}
+
+func describe(matchers []Matcher) string {
+	descs := make([]string, 0, len(matchers))
+	for _, matcher := range matchers {
+		descs = append(descs, matcher.desc)
+	}
+	return "{" + strings.Join(descs, ", ") + "}"
+}
@@ -0,0 +1,311 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	goLoggerHttp "github.com/pobyzaarif/go-logger/http"
+)
+
+type (
+	CassetteMode int
+
+	// Cassette is a http.RoundTripper that records request/response pairs into a fixture file
+	// or replays them without network.
+	Cassette struct {
+		Interactions []Interaction `json:"interactions"`
+
+		path  string
+		mode  CassetteMode
+		base  http.RoundTripper
+		used  []bool
+		mutex sync.Mutex
+	}
+
+	Interaction struct {
+		Request  CassetteRequest  `json:"request"`
+		Response CassetteResponse `json:"response"`
+	}
+
+	CassetteRequest struct {
+		Method string      `json:"method"`
+		URL    string      `json:"url"`
+		Header http.Header `json:"header"`
+		Body   string      `json:"body"`
+	}
+
+	CassetteResponse struct {
+		StatusCode int         `json:"status_code"`
+		Header     http.Header `json:"header"`
+		Body       string      `json:"body"`
+	}
+
+	// UnmatchedRequestError is returned in replay mode when no recorded interaction matches the request.
+	UnmatchedRequestError struct {
+		Request CassetteRequest
+		Diff    string
+	}
+)
+
+// CassetteMode possible values
+const (
+	ReplayCassetteMode CassetteMode = iota
+	RecordCassetteMode
+)
+
+// NewCassette creates a cassette backed by the fixture file at path.
+// In replay mode the file must exist, in record mode it is written by Save.
+// Requests and fixtures are redacted with the same hidden headers as the logs, and proxy config of Call is not applied.
+func NewCassette(path string, mode CassetteMode) (*Cassette, error) {
+	cassette := &Cassette{
+		path: path,
+		mode: mode,
+		base: http.DefaultTransport,
+	}
+
+	if mode == ReplayCassetteMode {
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(b, cassette); err != nil {
+			return nil, fmt.Errorf("invalid cassette %s: %w", path, err)
+		}
+		cassette.used = make([]bool, len(cassette.Interactions))
+	}
+
+	return cassette, nil
+}
+
+// UseCassette installs a cassette as the transport of Call.
+// stop restores the previous transport and, in record mode, saves the fixture file.
+// The transport is global, tests using a cassette cannot call t.Parallel: their requests would go through
+// each other's cassettes.
+func UseCassette(path string, mode CassetteMode) (stop func() error, err error) {
+	cassette, err := NewCassette(path, mode)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := currentTransport()
+	SetTransport(cassette)
+
+	return func() error {
+		SetTransport(previous)
+		if mode == RecordCassetteMode {
+			return cassette.Save()
+		}
+		return nil
+	}, nil
+}
+
+func (c *Cassette) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	cassetteRequest := CassetteRequest{
+		Method: req.Method,
+		URL:    req.URL.String(),
+		Header: redactHeader(req.Header),
+		Body:   goLoggerHttp.Redact(body, req.Header, goLoggerHttp.HiddenHeaders()),
+	}
+
+	if c.mode == RecordCassetteMode {
+		return c.record(req, cassetteRequest)
+	}
+
+	return c.replay(req, cassetteRequest)
+}
+
+func (c *Cassette) record(req *http.Request, cassetteRequest CassetteRequest) (*http.Response, error) {
+	res, err := c.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	buffer, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = ioutil.NopCloser(bytes.NewReader(buffer))
+
+	c.mutex.Lock()
+	c.Interactions = append(c.Interactions, Interaction{
+		Request: cassetteRequest,
+		Response: CassetteResponse{
+			StatusCode: res.StatusCode,
+			Header:     res.Header.Clone(),
+			Body:       string(buffer),
+		},
+	})
+	c.mutex.Unlock()
+
+	return res, nil
+}
+
+func (c *Cassette) replay(req *http.Request, cassetteRequest CassetteRequest) (*http.Response, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	closest, closestScore := -1, -1
+	for i, interaction := range c.Interactions {
+		score := matchScore(interaction.Request, cassetteRequest)
+		if score == 3 && !c.used[i] {
+			c.used[i] = true
+			return &http.Response{
+				Status:        fmt.Sprintf("%d %s", interaction.Response.StatusCode, http.StatusText(interaction.Response.StatusCode)),
+				StatusCode:    interaction.Response.StatusCode,
+				Proto:         "HTTP/1.1",
+				ProtoMajor:    1,
+				ProtoMinor:    1,
+				Header:        interaction.Response.Header.Clone(),
+				Body:          ioutil.NopCloser(strings.NewReader(interaction.Response.Body)),
+				ContentLength: int64(len(interaction.Response.Body)),
+				Request:       req,
+			}, nil
+		}
+		if score > closestScore {
+			closest, closestScore = i, score
+		}
+	}
+
+	unmatchedErr := &UnmatchedRequestError{Request: cassetteRequest}
+	if closest >= 0 {
+		unmatchedErr.Diff = diffRequest(c.Interactions[closest].Request, cassetteRequest)
+	}
+	return nil, unmatchedErr
+}
+
+// Save writes the recorded interactions into the fixture file.
+func (c *Cassette) Save() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	b, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(c.path, b, 0644)
+}
+
+func (e *UnmatchedRequestError) Error() string {
+	msg := fmt.Sprintf("cassette: no interaction matches %s %s", e.Request.Method, e.Request.URL)
+	if e.Diff == "" {
+		return msg + " (cassette is empty)"
+	}
+	return msg + ", closest recorded request (-recorded +actual):\n" + e.Diff
+}
+
+func readRequestBody(req *http.Request) (string, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return "", nil
+	}
+
+	buffer, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return "", err
+	}
+	req.Body = ioutil.NopCloser(bytes.NewReader(buffer))
+
+	return string(buffer), nil
+}
+
+func redactHeader(header http.Header) http.Header {
+	redacted := header.Clone()
+	for _, hiddenHeader := range goLoggerHttp.HiddenHeaders() {
+		if redacted.Get(hiddenHeader) != "" {
+			redacted.Set(hiddenHeader, "**hidden**")
+		}
+	}
+	return redacted
+}
+
+// matchScore counts matching method, url and body, a full match scores 3.
+func matchScore(recorded, actual CassetteRequest) int {
+	score := 0
+	if recorded.Method == actual.Method {
+		score++
+	}
+	if recorded.URL == actual.URL {
+		score++
+	}
+	if recorded.Body == actual.Body {
+		score++
+	}
+	return score
+}
+
+func diffRequest(recorded, actual CassetteRequest) string {
+	var sb strings.Builder
+	if recorded.Method != actual.Method {
+		fmt.Fprintf(&sb, "method:\n-%s\n+%s\n", recorded.Method, actual.Method)
+	}
+	if recorded.URL != actual.URL {
+		fmt.Fprintf(&sb, "url:\n-%s\n+%s\n", recorded.URL, actual.URL)
+	}
+	if recorded.Body != actual.Body {
+		sb.WriteString("body:\n")
+		sb.WriteString(diffLines(strings.Split(recorded.Body, "\n"), strings.Split(actual.Body, "\n")))
+	}
+	if sb.Len() == 0 {
+		return "(identical request already replayed)\n"
+	}
+	return sb.String()
+}
+
+// diffLines returns a minimal line diff between a and b based on their longest common subsequence.
+func diffLines(a, b []string) string {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var sb strings.Builder
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			sb.WriteString(" " + a[i] + "\n")
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			sb.WriteString("-" + a[i] + "\n")
+			i++
+		default:
+			sb.WriteString("+" + b[j] + "\n")
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		sb.WriteString("-" + a[i] + "\n")
+	}
+	for ; j < len(b); j++ {
+		sb.WriteString("+" + b[j] + "\n")
+	}
+	return sb.String()
+}
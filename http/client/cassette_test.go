@@ -0,0 +1,89 @@
+package http_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pobyzaarif/go-logger/gologgertest"
+	goLoggerClient "github.com/pobyzaarif/go-logger/http/client"
+)
+
+func TestCassetteRecordAndReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fixtures", "users.json")
+	server := gologgertest.NewServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"name":"alice"}`))
+	}))
+	url := server.URL + "/users/1"
+
+	call := func() (int, string, error) {
+		request := newRequest(t, http.MethodGet, url)
+		request.Header.Set("Authorization", "Bearer secret")
+		var body struct{ Name string }
+		status, err := goLoggerClient.Call(context.Background(), request, time.Second, goLoggerClient.JSONResponseBodyFormat, &body, nil)
+		return status, body.Name, err
+	}
+
+	stop, err := goLoggerClient.UseCassette(path, goLoggerClient.RecordCassetteMode)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status, name, err := call(); err != nil || status != http.StatusOK || name != "alice" {
+		t.Fatalf("record: got status %d, name %q and error %v", status, name, err)
+	}
+	if err := stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	fixture, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(fixture), "secret") {
+		t.Errorf("Authorization is not hidden in the fixture:\n%s", fixture)
+	}
+
+	// the fixture replays without network
+	server.Close()
+	stop, err = goLoggerClient.UseCassette(path, goLoggerClient.ReplayCassetteMode)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stop()
+
+	if status, name, err := call(); err != nil || status != http.StatusOK || name != "alice" {
+		t.Fatalf("replay: got status %d, name %q and error %v", status, name, err)
+	}
+	server.Sink.AssertLogged(t, gologgertest.Tag("OUTBOUND_REQUEST"), gologgertest.Message("success"))
+
+	// each interaction is replayed once
+	if _, _, err := call(); err == nil {
+		t.Fatal("replay: got no error for an interaction already used")
+	}
+}
+
+func TestCassetteUnmatchedRequest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "empty.json")
+	if err := ioutil.WriteFile(path, []byte(`{"interactions":[{"request":{"method":"GET","url":"http://example.com/users/1"},"response":{"status_code":200}}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cassette, err := goLoggerClient.NewCassette(path, goLoggerClient.ReplayCassetteMode)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = cassette.RoundTrip(newRequest(t, http.MethodGet, "http://example.com/users/2"))
+	var unmatched *goLoggerClient.UnmatchedRequestError
+	if !errors.As(err, &unmatched) {
+		t.Fatalf("got error %v, want an UnmatchedRequestError", err)
+	}
+	if !strings.Contains(unmatched.Diff, "/users/2") {
+		t.Errorf("diff does not show the unmatched URL:\n%s", unmatched.Diff)
+	}
+}
@@ -8,7 +8,9 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/http/httptrace"
 	"net/url"
+	"sync/atomic"
 	"time"
 
 	xmlToJson "github.com/basgys/goxml2json"
@@ -32,7 +34,33 @@ const (
 	XMLResponseBodyFormat
 )
 
-var logger = goLogger.NewLog("OUTBOUND_REQUEST")
+// transportValue wraps the transport, atomic.Value stores a single concrete type and no nil
+type transportValue struct {
+	rt http.RoundTripper
+}
+
+var (
+	logger      = goLogger.NewLog("OUTBOUND_REQUEST")
+	transport   atomic.Value
+	curlEnabled atomic.Value
+)
+
+// SetCurlEnabled adds a ready to run curl command of the request to the "net" section of every log.
+// It is safe to call while Call runs.
+func SetCurlEnabled(enabled bool) {
+	curlEnabled.Store(enabled)
+}
+
+// SetTransport sets the http.RoundTripper used by Call, nil restores http.DefaultTransport.
+// It is safe to call while Call runs, the calls already started keep their transport.
+func SetTransport(rt http.RoundTripper) {
+	transport.Store(transportValue{rt: rt})
+}
+
+func currentTransport() http.RoundTripper {
+	value, _ := transport.Load().(transportValue)
+	return value.rt
+}
 
 func Call(
 	ctx context.Context,
@@ -40,26 +68,46 @@ func Call(
 	timeout time.Duration,
 	responseBodyFormat ResponseBodyFormat,
 	responseBody interface{},
-	proxyConfig *ProxyConfig) (int, error) {
-	ctxTrackerID := ctx.Value("tracker_id")
-	trackerID := ""
-	if ctxTrackerID != nil {
-		trackerID = fmt.Sprintf("%v", ctxTrackerID)
-	}
+	proxyConfig *ProxyConfig) (statusCode int, err error) {
+	trackerID := ctxString(ctx, "tracker_id")
+	spanCtx, span := goLogger.StartSpan(ctx, request.Method+" "+request.URL.Host)
+	defer func() {
+		span.SetAttribute("response_http_code", statusCode)
+		if err != nil {
+			span.SetError(err)
+		}
+		span.End()
+	}()
+
+	// copy, so concurrent calls don't share the tracker and span IDs
+	logger := logger
+	logger.SetContext(spanCtx)
 	logger.SetTrackerID(trackerID)
+	propagate(ctx, request, trackerID)
 
+	rt := currentTransport()
 	var client http.Client
 	client.Timeout = timeout
+	client.Transport = rt
 
 	httpLog := map[string]interface{}{
+		"scheme":             request.URL.Scheme,
 		"host":               request.URL.Host,
 		"method":             request.Method,
 		"url":                request.URL.Path,
-		"request":            goLoggerHttp.DumpRequest(request, []string{"Authorization"}),
+		"request":            goLoggerHttp.DumpRequest(request, goLoggerHttp.HiddenHeaders()),
 		"response":           "",
 		"response_http_code": 0,
 	}
 
+	if enabled, _ := curlEnabled.Load().(bool); enabled {
+		curlConfig := goLoggerHttp.CurlConfig{Timeout: timeout}
+		if proxyConfig != nil {
+			curlConfig.Proxy = fmt.Sprintf("http://%s:%d", proxyConfig.Host, proxyConfig.Port)
+		}
+		httpLog["curl"] = goLoggerHttp.ToCurlWithConfig(request, curlConfig)
+	}
+
 	if proxyConfig != nil {
 		proxyUrl, err := url.Parse(fmt.Sprintf("http://%s:%d", proxyConfig.Host, proxyConfig.Port))
 		if err != nil {
@@ -68,14 +116,35 @@ func Call(
 			return -1, errors.New("failed to parse proxy url")
 		}
 
-		client.Transport = &http.Transport{Proxy: http.ProxyURL(proxyUrl)}
+		client.Transport = withProxy(rt, proxyUrl)
+	}
+
+	limitKey, wait, err := rateLimit(ctx, request)
+	if limitKey != "" {
+		httpLog["rate_limit"] = map[string]interface{}{
+			"key":       limitKey,
+			"wait_time": float64(wait.Nanoseconds()) / 1e6,
+			"rejected":  err != nil,
+		}
+	}
+	if err != nil {
+		logger.ErrorWithData("rejected by rate limiter", goLoggerHttp.NetworkLog(httpLog), err)
+
+		return 0, err
+	}
+	if wait > 0 {
+		logger.WarnWithData("delayed by rate limiter", goLoggerHttp.NetworkLog(httpLog))
 	}
 
 	logger.SetTimerStart(time.Now())
 
+	requestTiming := newTiming()
+	request = request.WithContext(httptrace.WithClientTrace(request.Context(), requestTiming.clientTrace()))
+
 	res, err := client.Do(request)
 
 	httpLog["response"] = goLoggerHttp.DumpResponse(res)
+	httpLog["timing"] = requestTiming.log()
 
 	if err != nil {
 		errMessage := "error is " + err.Error()
@@ -132,3 +201,17 @@ func Call(
 
 	return res.StatusCode, nil
 }
+
+// withProxy applies the proxy to the default or a *http.Transport, custom transports are kept as is.
+func withProxy(rt http.RoundTripper, proxyUrl *url.URL) http.RoundTripper {
+	switch t := rt.(type) {
+	case nil:
+		return &http.Transport{Proxy: http.ProxyURL(proxyUrl)}
+	case *http.Transport:
+		proxyTransport := t.Clone()
+		proxyTransport.Proxy = http.ProxyURL(proxyUrl)
+		return proxyTransport
+	default:
+		return rt
+	}
+}
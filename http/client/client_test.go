@@ -0,0 +1,198 @@
+package http_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pobyzaarif/go-logger/gologgertest"
+	goLoggerClient "github.com/pobyzaarif/go-logger/http/client"
+)
+
+func newRequest(t *testing.T, method, rawURL string) *http.Request {
+	t.Helper()
+	request, err := http.NewRequest(method, rawURL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return request
+}
+
+func TestCallLogsOutboundRequest(t *testing.T) {
+	trackerIDs := make(chan string, 1)
+	server := gologgertest.NewServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		trackerIDs <- r.Header.Get("X-Tracker-ID")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":1}`))
+	}))
+
+	ctx := context.WithValue(context.Background(), "tracker_id", "abc")
+	request := newRequest(t, http.MethodGet, server.URL+"/users/1")
+	request.Header.Set("Authorization", "Bearer secret")
+
+	var body struct{ ID int }
+	status, err := goLoggerClient.Call(ctx, request, time.Second, goLoggerClient.JSONResponseBodyFormat, &body, nil)
+	if err != nil || status != http.StatusOK || body.ID != 1 {
+		t.Fatalf("got status %d, body %+v and error %v", status, body, err)
+	}
+	if trackerID := <-trackerIDs; trackerID != "abc" {
+		t.Errorf("downstream got tracker ID %q, want abc", trackerID)
+	}
+
+	entries := server.Sink.Entries(
+		gologgertest.Tag("OUTBOUND_REQUEST"),
+		gologgertest.Message("success"),
+		gologgertest.TrackerID("abc"),
+		gologgertest.Field("net.url", "/users/1"),
+		gologgertest.Field("net.response_http_code", http.StatusOK),
+		gologgertest.HasField("net.timing"),
+	)
+	if len(entries) != 1 {
+		t.Fatalf("got %d success entries, want 1", len(entries))
+	}
+	dump, _ := entries[0].Field("net.request")
+	if s, _ := dump.(string); strings.Contains(s, "secret") || !strings.Contains(s, "**hidden**") {
+		t.Errorf("Authorization is not hidden in the request dump:\n%s", s)
+	}
+}
+
+func TestCallLogsCurlCommand(t *testing.T) {
+	goLoggerClient.SetCurlEnabled(true)
+	t.Cleanup(func() { goLoggerClient.SetCurlEnabled(false) })
+
+	server := gologgertest.NewServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	request := newRequest(t, http.MethodDelete, server.URL+"/users/1")
+	if _, err := goLoggerClient.Call(context.Background(), request, time.Second, goLoggerClient.RawResponseBodyFormat, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	entries := server.Sink.Entries(gologgertest.Tag("OUTBOUND_REQUEST"), gologgertest.HasField("net.curl"))
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries with a curl command, want 1", len(entries))
+	}
+	curl, _ := entries[0].Field("net.curl")
+	if s, _ := curl.(string); !strings.HasPrefix(s, "curl") || !strings.Contains(s, "DELETE") {
+		t.Errorf("got curl command %q", s)
+	}
+}
+
+func TestCallRateLimit(t *testing.T) {
+	server := gologgertest.NewServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	host := newRequest(t, http.MethodGet, server.URL).URL.Host
+
+	t.Run("rejected", func(t *testing.T) {
+		goLoggerClient.SetRateLimit(host, goLoggerClient.RateLimit{Rate: 0.001, Burst: 1})
+		t.Cleanup(func() { goLoggerClient.RemoveRateLimit(host) })
+		server.Sink.Reset()
+
+		for i := 0; i < 2; i++ {
+			_, err := goLoggerClient.Call(context.Background(), newRequest(t, http.MethodGet, server.URL), time.Second, goLoggerClient.RawResponseBodyFormat, nil, nil)
+			if i == 1 && !errors.Is(err, goLoggerClient.ErrRateLimited) {
+				t.Errorf("got error %v, want ErrRateLimited", err)
+			}
+		}
+
+		server.Sink.AssertLogged(t,
+			gologgertest.Message("rejected by rate limiter"),
+			gologgertest.Level("error"),
+			gologgertest.Field("net.rate_limit.rejected", true),
+		)
+		if n := len(server.Sink.Entries(gologgertest.Message("success"))); n != 1 {
+			t.Errorf("got %d sent requests, want 1", n)
+		}
+	})
+
+	t.Run("delayed", func(t *testing.T) {
+		goLoggerClient.SetRateLimit(host, goLoggerClient.RateLimit{Rate: 50, Burst: 1, Wait: true})
+		t.Cleanup(func() { goLoggerClient.RemoveRateLimit(host) })
+		server.Sink.Reset()
+
+		for i := 0; i < 2; i++ {
+			if _, err := goLoggerClient.Call(context.Background(), newRequest(t, http.MethodGet, server.URL), time.Second, goLoggerClient.RawResponseBodyFormat, nil, nil); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		server.Sink.AssertLogged(t,
+			gologgertest.Message("delayed by rate limiter"),
+			gologgertest.Level("warn"),
+			gologgertest.Field("net.rate_limit.key", host),
+		)
+		if n := len(server.Sink.Entries(gologgertest.Message("success"))); n != 2 {
+			t.Errorf("got %d sent requests, want 2", n)
+		}
+	})
+}
+
+func TestCallPropagatesTraceContext(t *testing.T) {
+	goLoggerClient.SetPropagationConfig(goLoggerClient.PropagationConfig{TraceContext: true})
+	t.Cleanup(func() { goLoggerClient.SetPropagationConfig(goLoggerClient.DefaultPropagationConfig) })
+
+	headers := make(chan http.Header, 1)
+	server := gologgertest.NewServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers <- r.Header.Clone()
+	}))
+
+	traceParent := "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"
+	ctx := context.WithValue(context.Background(), "traceparent", traceParent)
+	ctx = context.WithValue(ctx, "tracestate", "vendor=1")
+	if _, err := goLoggerClient.Call(ctx, newRequest(t, http.MethodGet, server.URL), time.Second, goLoggerClient.RawResponseBodyFormat, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	header := <-headers
+	if got := header.Get("traceparent"); !strings.HasPrefix(got, "00-0af7651916cd43dd8448eb211c80319c-") {
+		t.Errorf("got traceparent %q, want the trace ID of %q", got, traceParent)
+	}
+	if got := header.Get("tracestate"); got != "vendor=1" {
+		t.Errorf("got tracestate %q, want vendor=1", got)
+	}
+}
+
+func TestCallLogsTransportErrors(t *testing.T) {
+	gologgertest.Capture(t)
+
+	request := newRequest(t, http.MethodGet, "http://127.0.0.1:1/unreachable")
+	if _, err := goLoggerClient.Call(context.Background(), request, time.Second, goLoggerClient.RawResponseBodyFormat, nil, nil); err == nil {
+		t.Fatal("got no error for an unreachable host")
+	}
+
+	gologgertest.AssertLogged(t,
+		gologgertest.Tag("OUTBOUND_REQUEST"),
+		gologgertest.Message("failed on request"),
+		gologgertest.Level("error"),
+		gologgertest.Field("net.url", "/unreachable"),
+	)
+}
+
+func TestSetTransportWhileCalling(t *testing.T) {
+	server := gologgertest.NewServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	t.Cleanup(func() {
+		goLoggerClient.SetTransport(nil)
+		goLoggerClient.SetCurlEnabled(false)
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				request, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+				if _, err := goLoggerClient.Call(context.Background(), request, time.Second, goLoggerClient.RawResponseBodyFormat, nil, nil); err != nil {
+					t.Error(err)
+				}
+			}
+		}()
+	}
+	for i := 0; i < 10; i++ {
+		goLoggerClient.SetTransport(http.DefaultTransport)
+		goLoggerClient.SetCurlEnabled(i%2 == 0)
+		goLoggerClient.SetTransport(nil)
+	}
+	wg.Wait()
+}
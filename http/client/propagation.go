@@ -0,0 +1,68 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	goLoggerHttp "github.com/pobyzaarif/go-logger/http"
+)
+
+// PropagationConfig defines how Call forwards the tracker ID and trace context to the downstream service.
+type PropagationConfig struct {
+	// DisableTrackerID disables sending tracker_id from context.
+	// Optional. Default value false.
+	DisableTrackerID bool
+
+	// TrackerIDHeader is the outgoing header carrying tracker_id.
+	// Optional. Default value "X-Tracker-ID".
+	TrackerIDHeader string
+
+	// TraceContext enables W3C traceparent/tracestate propagation.
+	// Optional. Default value false.
+	TraceContext bool
+}
+
+var (
+	// DefaultPropagationConfig is the default propagation config used by Call.
+	DefaultPropagationConfig = PropagationConfig{
+		DisableTrackerID: false,
+		TrackerIDHeader:  goLoggerHttp.HeaderTrackerID,
+		TraceContext:     false,
+	}
+
+	propagation = DefaultPropagationConfig
+)
+
+// SetPropagationConfig replaces the propagation config used by Call.
+func SetPropagationConfig(config PropagationConfig) {
+	if config.TrackerIDHeader == "" {
+		config.TrackerIDHeader = DefaultPropagationConfig.TrackerIDHeader
+	}
+	propagation = config
+}
+
+func propagate(ctx context.Context, request *http.Request, trackerID string) {
+	if request.Header == nil {
+		request.Header = make(http.Header)
+	}
+
+	if !propagation.DisableTrackerID && trackerID != "" && request.Header.Get(propagation.TrackerIDHeader) == "" {
+		request.Header.Set(propagation.TrackerIDHeader, trackerID)
+	}
+
+	if propagation.TraceContext && request.Header.Get(goLoggerHttp.HeaderTraceParent) == "" {
+		request.Header.Set(goLoggerHttp.HeaderTraceParent, goLoggerHttp.NewTraceParent(ctxString(ctx, "traceparent"), trackerID))
+		if traceState := ctxString(ctx, "tracestate"); traceState != "" {
+			request.Header.Set(goLoggerHttp.HeaderTraceState, traceState)
+		}
+	}
+}
+
+func ctxString(ctx context.Context, key string) string {
+	val := ctx.Value(key)
+	if val == nil {
+		return ""
+	}
+	return fmt.Sprintf("%v", val)
+}
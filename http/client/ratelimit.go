@@ -0,0 +1,141 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type (
+	// RateLimit defines a token bucket limiting outbound calls of a host or a named endpoint.
+	RateLimit struct {
+		// Rate is the number of requests allowed per second.
+		Rate float64
+
+		// Burst is the maximum number of requests allowed at once.
+		// Optional. Default value max(1, Rate).
+		Burst int
+
+		// Wait waits for the bucket to refill instead of rejecting the call immediately,
+		// the wait never exceeds the context deadline.
+		// Optional. Default value false.
+		Wait bool
+
+		// MaxWait is the longest wait allowed when Wait is enabled.
+		// Optional. Default value 0 (bounded by the context deadline only).
+		MaxWait time.Duration
+	}
+
+	limiter struct {
+		limit  RateLimit
+		tokens float64
+		last   time.Time
+		mutex  sync.Mutex
+	}
+
+	rateLimitKey struct{}
+)
+
+// ErrRateLimited is returned by Call when the call is rejected by the rate limiter.
+var ErrRateLimited = errors.New("rate limited")
+
+var (
+	limiters      = make(map[string]*limiter)
+	limitersMutex sync.RWMutex
+)
+
+// SetRateLimit limits outbound calls of key, a request host (e.g. "api.partner.com") or an endpoint name set with WithRateLimitKey.
+func SetRateLimit(key string, limit RateLimit) {
+	if limit.Burst <= 0 {
+		limit.Burst = int(math.Max(1, math.Ceil(limit.Rate)))
+	}
+
+	limitersMutex.Lock()
+	limiters[key] = &limiter{limit: limit, tokens: float64(limit.Burst), last: time.Now()}
+	limitersMutex.Unlock()
+}
+
+// RemoveRateLimit removes the rate limit of key.
+func RemoveRateLimit(key string) {
+	limitersMutex.Lock()
+	delete(limiters, key)
+	limitersMutex.Unlock()
+}
+
+// WithRateLimitKey makes Call use the rate limit of a named endpoint (e.g. a merchant key) instead of the request host.
+func WithRateLimitKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, rateLimitKey{}, key)
+}
+
+// rateLimit waits for or rejects the call according to the limiter of the request,
+// it returns the limiter key (empty when not limited) and the time waited.
+func rateLimit(ctx context.Context, request *http.Request) (string, time.Duration, error) {
+	key, _ := ctx.Value(rateLimitKey{}).(string)
+
+	limitersMutex.RLock()
+	l, ok := limiters[key]
+	if !ok {
+		key = request.URL.Host
+		l, ok = limiters[key]
+	}
+	limitersMutex.RUnlock()
+	if !ok {
+		return "", 0, nil
+	}
+
+	wait, ok := l.reserve(ctx)
+	if !ok {
+		return key, 0, ErrRateLimited
+	}
+	if wait <= 0 {
+		return key, 0, nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return key, wait, nil
+	case <-ctx.Done():
+		l.cancel()
+		return key, wait, ErrRateLimited
+	}
+}
+
+// reserve takes a token and returns how long to wait for it, ok is false when the call must be rejected.
+func (l *limiter) reserve(ctx context.Context) (time.Duration, bool) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := time.Now()
+	l.tokens = math.Min(float64(l.limit.Burst), l.tokens+now.Sub(l.last).Seconds()*l.limit.Rate)
+	l.last = now
+
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0, true
+	}
+	if !l.limit.Wait || l.limit.Rate <= 0 {
+		return 0, false
+	}
+
+	wait := time.Duration((1 - l.tokens) / l.limit.Rate * float64(time.Second))
+	if l.limit.MaxWait > 0 && wait > l.limit.MaxWait {
+		return wait, false
+	}
+	if deadline, ok := ctx.Deadline(); ok && now.Add(wait).After(deadline) {
+		return wait, false
+	}
+
+	l.tokens--
+	return wait, true
+}
+
+func (l *limiter) cancel() {
+	l.mutex.Lock()
+	l.tokens++
+	l.mutex.Unlock()
+}
@@ -0,0 +1,81 @@
+package http
+
+import (
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// timing records the phases of an outbound request through httptrace.
+type timing struct {
+	start, dnsStart, dnsDone, connectStart, connectDone time.Time
+	tlsStart, tlsDone, gotConn, wroteRequest, firstByte time.Time
+	reused                                              bool
+	remoteAddr                                          string
+	mutex                                               sync.Mutex
+}
+
+func newTiming() *timing {
+	return &timing{start: time.Now()}
+}
+
+func (t *timing) clientTrace() *httptrace.ClientTrace {
+	set := func(field *time.Time) {
+		t.mutex.Lock()
+		if field.IsZero() {
+			*field = time.Now()
+		}
+		t.mutex.Unlock()
+	}
+
+	return &httptrace.ClientTrace{
+		DNSStart:          func(httptrace.DNSStartInfo) { set(&t.dnsStart) },
+		DNSDone:           func(httptrace.DNSDoneInfo) { set(&t.dnsDone) },
+		ConnectStart:      func(string, string) { set(&t.connectStart) },
+		ConnectDone:       func(string, string, error) { set(&t.connectDone) },
+		TLSHandshakeStart: func() { set(&t.tlsStart) },
+		TLSHandshakeDone:  func(tls.ConnectionState, error) { set(&t.tlsDone) },
+		GotConn: func(info httptrace.GotConnInfo) {
+			set(&t.gotConn)
+			t.mutex.Lock()
+			t.reused = info.Reused
+			if info.Conn != nil {
+				t.remoteAddr = info.Conn.RemoteAddr().String()
+			}
+			t.mutex.Unlock()
+		},
+		WroteRequest:         func(httptrace.WroteRequestInfo) { set(&t.wroteRequest) },
+		GotFirstResponseByte: func() { set(&t.firstByte) },
+	}
+}
+
+// log returns the timing breakdown in milliseconds, phases that did not happen are omitted.
+func (t *timing) log() map[string]interface{} {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	timingLog := map[string]interface{}{
+		"connection_reused": t.reused,
+		"remote_address":    t.remoteAddr,
+	}
+
+	durations := []struct {
+		name       string
+		start, end time.Time
+	}{
+		{"dns_lookup", t.dnsStart, t.dnsDone},
+		{"tcp_connect", t.connectStart, t.connectDone},
+		{"tls_handshake", t.tlsStart, t.tlsDone},
+		{"request_write", t.gotConn, t.wroteRequest},
+		{"server_processing", t.wroteRequest, t.firstByte},
+		{"time_to_first_byte", t.start, t.firstByte},
+	}
+	for _, d := range durations {
+		if !d.start.IsZero() && !d.end.IsZero() {
+			timingLog[d.name] = float64(d.end.Sub(d.start).Nanoseconds()) / 1e6
+		}
+	}
+
+	return timingLog
+}
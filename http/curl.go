@@ -0,0 +1,96 @@
+package http
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// CurlConfig defines the options of ToCurlWithConfig.
+type CurlConfig struct {
+	// HiddenHeaders are headers whose values are redacted from the command.
+	// Optional. Default value HiddenHeaders().
+	HiddenHeaders []string
+
+	// Proxy is the proxy url passed to --proxy.
+	// Optional. Default value "".
+	Proxy string
+
+	// Timeout is passed to --max-time.
+	// Optional. Default value 0 (no timeout).
+	Timeout time.Duration
+}
+
+// ToCurl returns a ready to run curl command reproducing req, with hidden headers redacted.
+func ToCurl(req *http.Request) string {
+	return ToCurlWithConfig(req, CurlConfig{})
+}
+
+// ToCurlWithConfig returns a ready to run curl command reproducing req.
+// The request body is read and restored so req can still be sent.
+func ToCurlWithConfig(req *http.Request, config CurlConfig) string {
+	if req == nil || req.URL == nil {
+		return ""
+	}
+	if config.HiddenHeaders == nil {
+		config.HiddenHeaders = HiddenHeaders()
+	}
+
+	command := []string{"curl", "-X", req.Method, shellQuote(redactURL(req.URL).String())}
+
+	names := make([]string, 0, len(req.Header))
+	for name := range req.Header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range req.Header[name] {
+			command = append(command, "-H", shellQuote(Redact(name+": "+value, req.Header, config.HiddenHeaders)))
+		}
+	}
+	if req.Host != "" && req.Host != req.URL.Host {
+		command = append(command, "-H", shellQuote("Host: "+req.Host))
+	}
+
+	if req.Body != nil && req.Body != http.NoBody {
+		body, err := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+		if err == nil && len(body) > 0 {
+			command = append(command, "--data-raw", shellQuote(Redact(string(body), req.Header, config.HiddenHeaders)))
+		}
+	}
+
+	if config.Proxy != "" {
+		command = append(command, "--proxy", shellQuote(config.Proxy))
+	}
+	if config.Timeout > 0 {
+		command = append(command, "--max-time", fmt.Sprintf("%g", config.Timeout.Seconds()))
+	}
+
+	return strings.Join(command, " ")
+}
+
+// redactURL returns a copy of u with its password hidden, or its user when there is no password (e.g. a token).
+func redactURL(u *url.URL) *url.URL {
+	if u.User == nil {
+		return u
+	}
+
+	redacted := *u
+	if _, ok := u.User.Password(); ok {
+		redacted.User = url.UserPassword(u.User.Username(), "**hidden**")
+	} else {
+		redacted.User = url.User("**hidden**")
+	}
+	return &redacted
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
@@ -0,0 +1,255 @@
+package har
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+
+	goLogger "github.com/pobyzaarif/go-logger/logger"
+)
+
+type (
+	GroupBy int
+
+	// Config defines the config for Collector.
+	Config struct {
+		// Dir is the directory HAR files are written to.
+		// Optional. Default value "har".
+		Dir string
+
+		// GroupBy selects one HAR file per tracker ID or per time window.
+		// Optional. Default value TrackerIDGroupBy.
+		GroupBy GroupBy
+
+		// Window is the length of a time window for TimeWindowGroupBy.
+		// Optional. Default value 1 minute.
+		Window time.Duration
+
+		// MaxGroups is the number of groups kept in memory, the oldest group is written and dropped beyond it.
+		// Optional. Default value 1000.
+		MaxGroups int
+
+		// MaxEntries is the number of entries kept per group, later entries of a full group are dropped.
+		// Optional. Default value 1000.
+		MaxEntries int
+
+		// FlushInterval is how often the HAR files of the groups changed since the last flush are written.
+		// Optional. Default value 5 seconds.
+		FlushInterval time.Duration
+	}
+
+	// Collector is an io.Writer receiving go-logger JSON lines, e.g. through
+	// logger.SetOutput(io.MultiWriter(os.Stdout, collector)), that writes HAR files in the background.
+	// Close writes the remaining groups.
+	Collector struct {
+		config Config
+		groups map[string][]Entry
+		order  []string
+		// dirty are the groups changed since the last flush, closed the dropped groups not written yet
+		dirty  map[string]bool
+		closed map[string][]Entry
+		mutex  sync.Mutex
+
+		// flushMutex keeps an older snapshot of a group from overwriting a newer one
+		flushMutex sync.Mutex
+		done       chan struct{}
+		closeOnce  sync.Once
+		wg         sync.WaitGroup
+	}
+)
+
+// GroupBy possible values
+const (
+	TrackerIDGroupBy GroupBy = iota
+	TimeWindowGroupBy
+)
+
+var (
+	// DefaultConfig is the default Collector config.
+	DefaultConfig = Config{
+		Dir:           "har",
+		GroupBy:       TrackerIDGroupBy,
+		Window:        time.Minute,
+		MaxGroups:     1000,
+		MaxEntries:    1000,
+		FlushInterval: 5 * time.Second,
+	}
+
+	unsafeFileName = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+	logger         = goLogger.NewLog("HAR")
+)
+
+// NewCollector creates a Collector and starts its flushing goroutine, Close stops it.
+func NewCollector(config Config) *Collector {
+	if config.Dir == "" {
+		config.Dir = DefaultConfig.Dir
+	}
+	if config.Window <= 0 {
+		config.Window = DefaultConfig.Window
+	}
+	if config.MaxGroups <= 0 {
+		config.MaxGroups = DefaultConfig.MaxGroups
+	}
+	if config.MaxEntries <= 0 {
+		config.MaxEntries = DefaultConfig.MaxEntries
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = DefaultConfig.FlushInterval
+	}
+
+	c := &Collector{
+		config: config,
+		groups: make(map[string][]Entry),
+		dirty:  make(map[string]bool),
+		closed: make(map[string][]Entry),
+		done:   make(chan struct{}),
+	}
+
+	c.wg.Add(1)
+	go c.run()
+
+	return c
+}
+
+// Write decodes log lines and adds every HTTP entry to its group, the HAR files are written by Flush.
+// Lines that are not HTTP entries are ignored.
+func (c *Collector) Write(p []byte) (int, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(p))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(p)+1)
+	for scanner.Scan() {
+		line := make(map[string]interface{})
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			continue
+		}
+
+		entry, ok := NewEntry(line)
+		if !ok {
+			continue
+		}
+
+		c.add(c.groupKey(line), entry)
+	}
+
+	return len(p), nil
+}
+
+// Flush writes the HAR files of the groups changed since the last flush.
+func (c *Collector) Flush() error {
+	c.flushMutex.Lock()
+	defer c.flushMutex.Unlock()
+
+	c.mutex.Lock()
+	pending := c.closed
+	c.closed = make(map[string][]Entry)
+	for key := range c.dirty {
+		pending[key] = append([]Entry{}, c.groups[key]...)
+	}
+	c.dirty = make(map[string]bool)
+	c.mutex.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+	if err := os.MkdirAll(c.config.Dir, 0755); err != nil {
+		return err
+	}
+
+	var firstErr error
+	for key, entries := range pending {
+		if err := c.writeFile(key, entries); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Close stops the flushing goroutine and writes the remaining groups.
+func (c *Collector) Close() error {
+	c.closeOnce.Do(func() {
+		close(c.done)
+	})
+	c.wg.Wait()
+	return c.Flush()
+}
+
+func (c *Collector) run() {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(c.config.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := c.Flush(); err != nil {
+				logger.WarnWithDataAndError("failed to write HAR files", map[string]interface{}{"dir": c.config.Dir}, err)
+			}
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// HAR returns the HAR document of a group, a tracker ID or a window start time in "20060102T150405Z" format.
+func (c *Collector) HAR(key string) HAR {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return newHAR(append([]Entry{}, c.groups[key]...))
+}
+
+func (c *Collector) groupKey(line map[string]interface{}) string {
+	if c.config.GroupBy == TimeWindowGroupBy {
+		start, ok := goLogger.ParseTime(line["timer_start"])
+		if !ok {
+			start = time.Now()
+		}
+		return start.UTC().Truncate(c.config.Window).Format("20060102T150405Z")
+	}
+
+	trackerID := stringValue(line["tracker_id"])
+	if trackerID == "" {
+		return "no_tracker_id"
+	}
+	return trackerID
+}
+
+func (c *Collector) add(key string, entry Entry) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if _, ok := c.groups[key]; !ok {
+		c.order = append(c.order, key)
+		if len(c.order) > c.config.MaxGroups {
+			oldest := c.order[0]
+			if c.dirty[oldest] {
+				c.closed[oldest] = c.groups[oldest]
+				delete(c.dirty, oldest)
+			}
+			delete(c.groups, oldest)
+			c.order = c.order[1:]
+		}
+	}
+	if len(c.groups[key]) >= c.config.MaxEntries {
+		return
+	}
+	c.groups[key] = append(c.groups[key], entry)
+	c.dirty[key] = true
+}
+
+func (c *Collector) writeFile(key string, entries []Entry) error {
+	b, err := json.MarshalIndent(newHAR(entries), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	fileName := fmt.Sprintf("%s.har", unsafeFileName.ReplaceAllString(key, "_"))
+	return ioutil.WriteFile(filepath.Join(c.config.Dir, fileName), b, 0644)
+}
@@ -0,0 +1,101 @@
+package har
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func outboundJSON(t *testing.T, trackerID string) []byte {
+	t.Helper()
+	line := outboundLine("success", map[string]interface{}{
+		"method":             "GET",
+		"host":               "example.com",
+		"request":            "GET /users HTTP/1.1\r\nHost: example.com\r\n\r\n",
+		"response_http_code": 200,
+		"timing":             map[string]interface{}{"wait": 1},
+	})
+	line["tracker_id"] = trackerID
+	b, err := json.Marshal(line)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return append(b, '\n')
+}
+
+func readHAR(t *testing.T, path string) HAR {
+	t.Helper()
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var har HAR
+	if err := json.Unmarshal(b, &har); err != nil {
+		t.Fatal(err)
+	}
+	return har
+}
+
+func TestCollectorWritesOnFlushAndClose(t *testing.T) {
+	dir := t.TempDir()
+	collector := NewCollector(Config{Dir: dir, MaxEntries: 2, FlushInterval: time.Hour})
+
+	for i := 0; i < 3; i++ {
+		if _, err := collector.Write(outboundJSON(t, "abc")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "abc.har")); !os.IsNotExist(err) {
+		t.Fatalf("HAR file written by Write, stat error %v", err)
+	}
+
+	if err := collector.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(readHAR(t, filepath.Join(dir, "abc.har")).Log.Entries); n != 2 {
+		t.Errorf("got %d entries, want MaxEntries 2", n)
+	}
+}
+
+func TestCollectorWritesDroppedGroups(t *testing.T) {
+	dir := t.TempDir()
+	collector := NewCollector(Config{Dir: dir, MaxGroups: 1, FlushInterval: time.Hour})
+	defer collector.Close()
+
+	collector.Write(outboundJSON(t, "first"))
+	collector.Write(outboundJSON(t, "second"))
+	if err := collector.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{"first", "second"} {
+		if n := len(readHAR(t, filepath.Join(dir, key+".har")).Log.Entries); n != 1 {
+			t.Errorf("group %s: got %d entries, want 1", key, n)
+		}
+	}
+	if n := len(collector.HAR("first").Log.Entries); n != 0 {
+		t.Errorf("dropped group still has %d entries in memory", n)
+	}
+}
+
+func TestCollectorFlushesOnInterval(t *testing.T) {
+	dir := t.TempDir()
+	collector := NewCollector(Config{Dir: dir, FlushInterval: 10 * time.Millisecond})
+	defer collector.Close()
+
+	collector.Write(outboundJSON(t, "abc"))
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "abc.har")); err == nil {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("HAR file not written after the flush interval")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
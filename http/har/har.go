@@ -0,0 +1,343 @@
+package har
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/textproto"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	goLogger "github.com/pobyzaarif/go-logger/logger"
+)
+
+// HAR is an HTTP Archive 1.2 document.
+type (
+	HAR struct {
+		Log Log `json:"log"`
+	}
+
+	Log struct {
+		Version string  `json:"version"`
+		Creator Creator `json:"creator"`
+		Entries []Entry `json:"entries"`
+	}
+
+	Creator struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	}
+
+	Entry struct {
+		StartedDateTime string   `json:"startedDateTime"`
+		Time            float64  `json:"time"`
+		Request         Request  `json:"request"`
+		Response        Response `json:"response"`
+		Cache           struct{} `json:"cache"`
+		Timings         Timings  `json:"timings"`
+		ServerIPAddress string   `json:"serverIPAddress,omitempty"`
+		Comment         string   `json:"comment,omitempty"`
+	}
+
+	Request struct {
+		Method      string      `json:"method"`
+		URL         string      `json:"url"`
+		HTTPVersion string      `json:"httpVersion"`
+		Cookies     []Cookie    `json:"cookies"`
+		Headers     []NameValue `json:"headers"`
+		QueryString []NameValue `json:"queryString"`
+		PostData    *PostData   `json:"postData,omitempty"`
+		HeadersSize int         `json:"headersSize"`
+		BodySize    int         `json:"bodySize"`
+	}
+
+	Response struct {
+		Status      int         `json:"status"`
+		StatusText  string      `json:"statusText"`
+		HTTPVersion string      `json:"httpVersion"`
+		Cookies     []Cookie    `json:"cookies"`
+		Headers     []NameValue `json:"headers"`
+		Content     Content     `json:"content"`
+		RedirectURL string      `json:"redirectURL"`
+		HeadersSize int         `json:"headersSize"`
+		BodySize    int         `json:"bodySize"`
+	}
+
+	Cookie struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	}
+
+	NameValue struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	}
+
+	PostData struct {
+		MimeType string `json:"mimeType"`
+		Text     string `json:"text"`
+	}
+
+	Content struct {
+		Size     int    `json:"size"`
+		MimeType string `json:"mimeType"`
+		Text     string `json:"text"`
+	}
+
+	Timings struct {
+		Blocked float64 `json:"blocked"`
+		DNS     float64 `json:"dns"`
+		Connect float64 `json:"connect"`
+		Send    float64 `json:"send"`
+		Wait    float64 `json:"wait"`
+		Receive float64 `json:"receive"`
+		SSL     float64 `json:"ssl"`
+	}
+)
+
+func newHAR(entries []Entry) HAR {
+	return HAR{Log: Log{
+		Version: "1.2",
+		Creator: Creator{Name: "go-logger", Version: "1.0"},
+		Entries: entries,
+	}}
+}
+
+// NewEntry converts an OUTBOUND_REQUEST or INBOUND_REQUEST log line into a HAR entry.
+func NewEntry(line map[string]interface{}) (Entry, bool) {
+	tag, _ := line["tag"].(string)
+	data, _ := line["data"].(map[string]interface{})
+	netLog, _ := data["net"].(map[string]interface{})
+	if netLog == nil || (tag != "OUTBOUND_REQUEST" && tag != "INBOUND_REQUEST") {
+		return Entry{}, false
+	}
+	// outbound lines logged before sending, e.g. delayed or rejected by the rate limiter, have no timing
+	if tag == "OUTBOUND_REQUEST" && netLog["timing"] == nil {
+		return Entry{}, false
+	}
+
+	scheme, _ := netLog["scheme"].(string)
+	if scheme == "" {
+		scheme = "http"
+	}
+	host, _ := netLog["host"].(string)
+	status, _ := netLog["response_http_code"].(float64)
+
+	var request Request
+	var response Response
+	if tag == "OUTBOUND_REQUEST" {
+		dump, _ := netLog["request"].(string)
+		request = parseRequest(dump, scheme, host, "")
+		dump, _ = netLog["response"].(string)
+		response = parseResponse(dump, int(status))
+	} else {
+		dump, _ := netLog["request_header"].(string)
+		body, _ := netLog["request"].(string)
+		request = parseRequest(dump, scheme, host, body)
+		responseHeader := http.Header{}
+		if s, _ := netLog["response_header"].(string); s != "" {
+			_ = json.Unmarshal([]byte(s), &responseHeader)
+		}
+		body, _ = netLog["response"].(string)
+		response = newResponse(int(status), "HTTP/1.1", responseHeader, body)
+	}
+
+	entry := Entry{
+		Request:  request,
+		Response: response,
+	}
+
+	if start, ok := goLogger.ParseTime(line["timer_start"]); ok {
+		entry.StartedDateTime = start.Format(time.RFC3339Nano)
+	}
+	if processingTime, ok := goLogger.ParseProcessingTime(line); ok {
+		entry.Time = float64(processingTime) / float64(time.Millisecond)
+	}
+	entry.Timings = Timings{Blocked: -1, DNS: -1, Connect: -1, SSL: -1, Wait: entry.Time}
+	if timing, _ := netLog["timing"].(map[string]interface{}); timing != nil {
+		entry.Timings = newTimings(timing, entry.Time)
+		if remote, _ := timing["remote_address"].(string); remote != "" {
+			if serverIP, _, err := net.SplitHostPort(remote); err == nil {
+				entry.ServerIPAddress = serverIP
+			}
+		}
+	}
+	if errMessage, _ := line["error"].(string); errMessage != "" {
+		entry.Comment = errMessage
+	}
+
+	return entry, true
+}
+
+// newTimings maps the outbound "timing" section into HAR timings, missing phases are -1.
+func newTimings(timing map[string]interface{}, total float64) Timings {
+	phase := func(name string) float64 {
+		if v, ok := timing[name].(float64); ok {
+			return v
+		}
+		return -1
+	}
+
+	timings := Timings{
+		Blocked: -1,
+		DNS:     phase("dns_lookup"),
+		Connect: phase("tcp_connect"),
+		SSL:     phase("tls_handshake"),
+		Send:    phase("request_write"),
+		Wait:    phase("server_processing"),
+	}
+	if timings.Connect >= 0 && timings.SSL >= 0 {
+		// HAR connect time includes the ssl handshake
+		timings.Connect += timings.SSL
+	}
+	if timings.Send < 0 {
+		timings.Send = 0
+	}
+	if timings.Wait < 0 {
+		timings.Wait = total
+	}
+	if ttfb := phase("time_to_first_byte"); ttfb >= 0 && total > ttfb {
+		timings.Receive = total - ttfb
+	}
+
+	return timings
+}
+
+func parseRequest(dump, scheme, host, body string) Request {
+	request := Request{
+		HTTPVersion: "HTTP/1.1",
+		Cookies:     []Cookie{},
+		Headers:     []NameValue{},
+		QueryString: []NameValue{},
+		HeadersSize: -1,
+		BodySize:    -1,
+	}
+
+	head, dumpBody, header := splitDump(dump)
+	if body == "" {
+		body = dumpBody
+	}
+
+	requestLine := strings.SplitN(head, " ", 3)
+	if len(requestLine) == 3 {
+		request.Method, request.HTTPVersion = requestLine[0], requestLine[2]
+		if u, err := url.ParseRequestURI(requestLine[1]); err == nil {
+			if header.Get("Host") != "" {
+				host = header.Get("Host")
+			}
+			u.Scheme, u.Host = scheme, host
+			request.URL = u.String()
+			for name, values := range u.Query() {
+				for _, value := range values {
+					request.QueryString = append(request.QueryString, NameValue{Name: name, Value: value})
+				}
+			}
+		}
+	}
+
+	request.Headers = headers(header)
+	request.Cookies = cookies((&http.Request{Header: header}).Cookies())
+	if body != "" {
+		request.BodySize = len(body)
+		request.PostData = &PostData{MimeType: header.Get("Content-Type"), Text: body}
+	} else {
+		request.BodySize = 0
+	}
+
+	return request
+}
+
+func parseResponse(dump string, status int) Response {
+	head, body, header := splitDump(dump)
+	proto := "HTTP/1.1"
+	if statusLine := strings.SplitN(head, " ", 3); len(statusLine) >= 2 {
+		proto = statusLine[0]
+		if code, err := strconv.Atoi(statusLine[1]); err == nil && status == 0 {
+			status = code
+		}
+	}
+
+	if strings.EqualFold(header.Get("Transfer-Encoding"), "chunked") {
+		if b, err := ioutil.ReadAll(httputil.NewChunkedReader(strings.NewReader(body))); err == nil {
+			body = string(b)
+		}
+	}
+
+	return newResponse(status, proto, header, body)
+}
+
+func newResponse(status int, proto string, header http.Header, body string) Response {
+	return Response{
+		Status:      status,
+		StatusText:  http.StatusText(status),
+		HTTPVersion: proto,
+		Cookies:     cookies((&http.Response{Header: header}).Cookies()),
+		Headers:     headers(header),
+		Content: Content{
+			Size:     len(body),
+			MimeType: header.Get("Content-Type"),
+			Text:     body,
+		},
+		HeadersSize: -1,
+		BodySize:    len(body),
+	}
+}
+
+// splitDump splits an httputil dump into its first line, body and headers.
+func splitDump(dump string) (string, string, http.Header) {
+	header := http.Header{}
+	if dump == "" {
+		return "", "", header
+	}
+
+	head, body := dump, ""
+	if i := strings.Index(dump, "\r\n\r\n"); i >= 0 {
+		head, body = dump[:i+2], dump[i+4:]
+	}
+
+	reader := textproto.NewReader(bufio.NewReader(strings.NewReader(head + "\r\n")))
+	firstLine, err := reader.ReadLine()
+	if err != nil {
+		return "", body, header
+	}
+	if mimeHeader, err := reader.ReadMIMEHeader(); err == nil || len(mimeHeader) > 0 {
+		header = http.Header(mimeHeader)
+	}
+
+	return firstLine, body, header
+}
+
+func headers(header http.Header) []NameValue {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	nameValues := []NameValue{}
+	for _, name := range names {
+		for _, value := range header[name] {
+			nameValues = append(nameValues, NameValue{Name: name, Value: value})
+		}
+	}
+	return nameValues
+}
+
+func cookies(httpCookies []*http.Cookie) []Cookie {
+	harCookies := []Cookie{}
+	for _, cookie := range httpCookies {
+		harCookies = append(harCookies, Cookie{Name: cookie.Name, Value: cookie.Value})
+	}
+	return harCookies
+}
+
+func stringValue(v interface{}) string {
+	s, _ := v.(string)
+	return s
+}
@@ -0,0 +1,42 @@
+package har
+
+import (
+	"testing"
+)
+
+func outboundLine(message string, net map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"tag":     "OUTBOUND_REQUEST",
+		"message": message,
+		"data":    map[string]interface{}{"net": net},
+	}
+}
+
+func TestNewEntrySkipsLinesLoggedBeforeSending(t *testing.T) {
+	net := map[string]interface{}{
+		"method":     "GET",
+		"host":       "example.com",
+		"request":    "GET /users HTTP/1.1\r\nHost: example.com\r\n\r\n",
+		"rate_limit": map[string]interface{}{"key": "example", "wait_time": 10.0},
+	}
+	for _, message := range []string{"delayed by rate limiter", "rejected by rate limiter"} {
+		if _, ok := NewEntry(outboundLine(message, net)); ok {
+			t.Errorf("%q line became a HAR entry", message)
+		}
+	}
+
+	sent := map[string]interface{}{
+		"response_http_code": 200.0,
+		"timing":             map[string]interface{}{"wait": 1.0},
+	}
+	for k, v := range net {
+		sent[k] = v
+	}
+	entry, ok := NewEntry(outboundLine("success", sent))
+	if !ok {
+		t.Fatal("sent request did not become a HAR entry")
+	}
+	if entry.Response.Status != 200 {
+		t.Errorf("got status %d, want 200", entry.Response.Status)
+	}
+}
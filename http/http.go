@@ -6,8 +6,28 @@ import (
 	"net/http"
 	"net/http/httputil"
 	"strings"
+	"sync/atomic"
 )
 
+// DefaultHiddenHeaders are request headers whose values are redacted from logs and fixtures,
+// until SetHiddenHeaders is called. Use SetHiddenHeaders to change them while logging.
+var DefaultHiddenHeaders = []string{"Authorization"}
+
+var hiddenHeaders atomic.Value
+
+// HiddenHeaders returns the request headers whose values are redacted from logs and fixtures.
+func HiddenHeaders() []string {
+	if headers, ok := hiddenHeaders.Load().([]string); ok {
+		return headers
+	}
+	return DefaultHiddenHeaders
+}
+
+// SetHiddenHeaders replaces the hidden headers, it is safe to call while logging.
+func SetHiddenHeaders(headers []string) {
+	hiddenHeaders.Store(append([]string{}, headers...))
+}
+
 func DumpRequest(req *http.Request, hiddenHeaders []string) string {
 	if req == nil {
 		return ""
@@ -20,16 +40,19 @@ func DumpRequest(req *http.Request, hiddenHeaders []string) string {
 		return fmt.Sprintf("%+v", req)
 	}
 
-	stringDump := string(requestDump)
+	return Redact(string(requestDump), req.Header, hiddenHeaders)
+}
 
+// Redact replaces every occurrence of the hidden headers values in s.
+func Redact(s string, header http.Header, hiddenHeaders []string) string {
 	for _, hiddenHeader := range hiddenHeaders {
-		val := req.Header.Get(hiddenHeader)
+		val := header.Get(hiddenHeader)
 		if val != "" {
-			stringDump = strings.Replace(stringDump, val, "**hidden**", -1)
+			s = strings.Replace(s, val, "**hidden**", -1)
 		}
 	}
 
-	return stringDump
+	return s
 }
 
 func DumpResponse(resp *http.Response) string {
@@ -0,0 +1,68 @@
+package http
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Header names used to propagate tracker ID and W3C trace context between services.
+const (
+	HeaderTrackerID   = "X-Tracker-ID"
+	HeaderTraceParent = "traceparent"
+	HeaderTraceState  = "tracestate"
+)
+
+// NewTraceParent builds a W3C traceparent value for an outgoing request.
+// parent is the incoming traceparent (if any) whose trace-id is kept, otherwise
+// the trace-id is derived from trackerID when it is a UUID or generated randomly.
+func NewTraceParent(parent, trackerID string) string {
+	traceID, _, flags, ok := ParseTraceParent(parent)
+	if !ok {
+		traceID = strings.ToLower(strings.ReplaceAll(trackerID, "-", ""))
+		if !isHex(traceID, 32) || isZero(traceID) {
+			traceID = randomHex(16)
+		}
+		flags = "01"
+	}
+
+	return fmt.Sprintf("00-%s-%s-%s", traceID, randomHex(8), flags)
+}
+
+// ParseTraceParent splits a W3C traceparent value into trace-id, parent-id and flags.
+func ParseTraceParent(traceParent string) (traceID, parentID, flags string, ok bool) {
+	parts := strings.Split(strings.TrimSpace(traceParent), "-")
+	if len(parts) != 4 || !isHex(parts[0], 2) || parts[0] == "ff" {
+		return "", "", "", false
+	}
+
+	traceID, parentID, flags = parts[1], parts[2], parts[3]
+	if !isHex(traceID, 32) || isZero(traceID) || !isHex(parentID, 16) || isZero(parentID) || !isHex(flags, 2) {
+		return "", "", "", false
+	}
+
+	return traceID, parentID, flags, true
+}
+
+func isHex(s string, length int) bool {
+	if len(s) != length {
+		return false
+	}
+	for _, c := range s {
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+func isZero(s string) bool {
+	return strings.Trim(s, "0") == ""
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,133 @@
+package logger
+
+import (
+	"fmt"
+	"path"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync"
+)
+
+type (
+	CallerPath int
+
+	// CallerConfig defines how the caller of a log entry is reported.
+	CallerConfig struct {
+		// Disable skips the caller capture, "caller" is logged empty.
+		// Optional. Default value false.
+		Disable bool
+
+		// Path is the format of the caller file path.
+		// Optional. Default value FullCallerPath.
+		Path CallerPath
+
+		// Function adds the caller function name as "caller_function".
+		// Optional. Default value false.
+		Function bool
+	}
+)
+
+// CallerPath possible values
+const (
+	// FullCallerPath is the absolute path of the file at build time, e.g. "/home/me/app/order/service.go:12".
+	FullCallerPath CallerPath = iota
+	// ModuleCallerPath is the path relative to the main module, e.g. "order/service.go:12".
+	ModuleCallerPath
+	// ShortCallerPath is the package directory and file, e.g. "order/service.go:12" for any module depth.
+	ShortCallerPath
+)
+
+var (
+	// DefaultCallerConfig is the default caller config.
+	DefaultCallerConfig = CallerConfig{
+		Disable:  false,
+		Path:     FullCallerPath,
+		Function: false,
+	}
+
+	callerConfig      = DefaultCallerConfig
+	callerConfigMutex sync.RWMutex
+
+	// mainModule and mainPackage are the import paths of the main module and of the main package
+	mainModule, mainPackage = func() (string, string) {
+		if info, ok := debug.ReadBuildInfo(); ok {
+			return info.Main.Path, info.Path
+		}
+		return "", ""
+	}()
+)
+
+// SetCallerConfig replaces the caller config.
+func SetCallerConfig(config CallerConfig) {
+	callerConfigMutex.Lock()
+	callerConfig = config
+	callerConfigMutex.Unlock()
+}
+
+func currentCallerConfig() CallerConfig {
+	callerConfigMutex.RLock()
+	defer callerConfigMutex.RUnlock()
+	return callerConfig
+}
+
+// WithCallerSkip returns a copy of the log reporting the caller skip frames higher,
+// for helpers wrapping the log methods.
+func (newLog newLog) WithCallerSkip(skip int) newLog {
+	newLog.callerSkip += skip
+	return newLog
+}
+
+// caller returns the file:line and function skip frames above its caller.
+func caller(skip int, config CallerConfig) (string, string) {
+	pc, file, line, ok := runtime.Caller(skip + 1)
+	if !ok {
+		return "", ""
+	}
+
+	function := ""
+	if fn := runtime.FuncForPC(pc); fn != nil {
+		function = fn.Name()
+	}
+
+	switch config.Path {
+	case ModuleCallerPath:
+		file = moduleFile(function, file)
+	case ShortCallerPath:
+		file = path.Join(path.Base(path.Dir(file)), path.Base(file))
+	}
+
+	return fmt.Sprintf("%v:%v", file, line), function
+}
+
+// moduleFile returns the path of file relative to the main module, the package import path of function
+// followed by the file name. Functions of package main are named "main.f", the import path is the one of the build info.
+func moduleFile(function, file string) string {
+	pkg := packageOf(function)
+	if pkg == "main" {
+		pkg = mainPackage
+		// built from files, e.g. "go run main.go", the package has no import path
+		if pkg == "" || pkg == "command-line-arguments" {
+			return path.Base(file)
+		}
+	}
+	if pkg == "" {
+		return file
+	}
+
+	file = pkg + "/" + path.Base(file)
+	if mainModule != "" && strings.HasPrefix(file, mainModule+"/") {
+		file = strings.TrimPrefix(file, mainModule+"/")
+	}
+	return file
+}
+
+// packageOf returns the import path of a function name such as "github.com/me/app/order.(*Service).Create".
+func packageOf(function string) string {
+	lastSlash := strings.LastIndex(function, "/")
+	dot := strings.Index(function[lastSlash+1:], ".")
+	if dot < 0 {
+		return ""
+	}
+	return function[:lastSlash+1+dot]
+}
@@ -0,0 +1,110 @@
+package logger
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// line returns the file and line of its caller.
+func line() (string, int) {
+	_, file, line, _ := runtime.Caller(1)
+	return file, line
+}
+
+func infoFromHelper(log newLog) {
+	log = log.WithCallerSkip(1)
+	log.Info("helper")
+}
+
+func TestCallerPath(t *testing.T) {
+	tests := []struct {
+		name string
+		path CallerPath
+		want func(file string, line int) string
+	}{
+		{"full", FullCallerPath, func(file string, line int) string {
+			return fmt.Sprintf("%s:%d", file, line)
+		}},
+		{"module", ModuleCallerPath, func(file string, line int) string {
+			return fmt.Sprintf("logger/caller_test.go:%d", line)
+		}},
+		{"short", ShortCallerPath, func(file string, line int) string {
+			return fmt.Sprintf("logger/caller_test.go:%d", line)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			observer := ReplaceGlobalsForTest(t)
+			SetCallerConfig(CallerConfig{Path: tt.path, Function: true})
+
+			log := NewLog("TEST")
+			file, line := line()
+			log.Info("direct")
+			infoFromHelper(log)
+
+			entries := observer.All()
+			if len(entries) != 2 {
+				t.Fatalf("got %d entries, want 2", len(entries))
+			}
+			if got, want := entries[0].Fields["caller"], tt.want(file, line+1); got != want {
+				t.Errorf("got caller %v, want %v", got, want)
+			}
+			if got, want := entries[1].Fields["caller"], tt.want(file, line+2); got != want {
+				t.Errorf("WithCallerSkip: got caller %v, want %v", got, want)
+			}
+			if got, want := entries[0].Fields["caller_function"], packagePath+".TestCallerPath.func4"; got != want {
+				t.Errorf("got caller_function %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestCallerDisabled(t *testing.T) {
+	observer := ReplaceGlobalsForTest(t)
+	SetCallerConfig(CallerConfig{Disable: true})
+
+	log := NewLog("TEST")
+	log.Info("message")
+	if got := observer.All()[0].Fields["caller"]; got != "" {
+		t.Errorf("got caller %v, want it empty", got)
+	}
+}
+
+func TestModuleFileOfPackageMain(t *testing.T) {
+	module, pkg := mainModule, mainPackage
+	t.Cleanup(func() { mainModule, mainPackage = module, pkg })
+
+	file := filepath.Join("/home/me/app", "cmd", "server", "main.go")
+	tests := []struct {
+		module, pkg, function, want string
+	}{
+		{"github.com/me/app", "github.com/me/app", "main.main", "main.go"},
+		{"github.com/me/app", "github.com/me/app/cmd/server", "main.(*server).run", "cmd/server/main.go"},
+		{"", "command-line-arguments", "main.main", "main.go"},
+		{"github.com/me/app", "github.com/me/app", "github.com/me/app/order.(*Service).Create", "order/main.go"},
+		{"github.com/me/app", "github.com/me/app", "github.com/other/lib.Do", "github.com/other/lib/main.go"},
+	}
+	for _, tt := range tests {
+		mainModule, mainPackage = tt.module, tt.pkg
+		if got := moduleFile(tt.function, file); got != tt.want {
+			t.Errorf("moduleFile(%q) in package %q: got %q, want %q", tt.function, tt.pkg, got, tt.want)
+		}
+	}
+}
+
+func TestPackageOf(t *testing.T) {
+	tests := map[string]string{
+		"main.main": "main",
+		"github.com/me/app/order.(*Service).Create": "github.com/me/app/order",
+		"github.com/me/app/order.New.func1":         "github.com/me/app/order",
+		"github.com/me/app.v2/order.Create":         "github.com/me/app.v2/order",
+		"runtime":                                   "",
+	}
+	for function, want := range tests {
+		if got := packageOf(function); got != want {
+			t.Errorf("packageOf(%q): got %q, want %q", function, got, want)
+		}
+	}
+}
@@ -0,0 +1,488 @@
+package logger
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	goLoggerAppName "github.com/pobyzaarif/go-logger/appname"
+	goLoggerHttp "github.com/pobyzaarif/go-logger/http"
+	"gopkg.in/yaml.v3"
+)
+
+type (
+	// Config is the global logger configuration, see Configure, LoadConfig and ConfigFromEnv.
+	Config struct {
+		// Level is the lowest level written: "info", "warn", "error" or "fatal".
+		// Optional. Default value "info".
+		Level string `json:"level" yaml:"level"`
+
+		// TagLevels overrides Level per tag, e.g. {"MONGO_QUERY": "warn"}.
+		// Optional. Default value nil.
+		TagLevels map[string]string `json:"tag_levels" yaml:"tag_levels"`
+
+		// Outputs are "stdout", "stderr" or file paths (opened in append mode).
+		// Optional. Default value ["stdout"].
+		Outputs []string `json:"outputs" yaml:"outputs"`
+
+		// Format is "json" or "text".
+		// Optional. Default value "json".
+		Format string `json:"format" yaml:"format"`
+
+		// TimeFormat is the format of every time field, including the time.Time values of data:
+		// "rfc3339", "rfc3339nano", "unix", "unix_ms", "unix_nano" or a Go layout,
+		// a layout that does not read its own output back, e.g. "RFC1123" instead of time.RFC1123, is rejected.
+		// Optional. Default value "rfc3339nano".
+		TimeFormat string `json:"time_format" yaml:"time_format"`
+
+		// TimeZone is "Local", "UTC", an IANA zone name such as "Asia/Jakarta" or a fixed offset such as "+07:00".
+		// Optional. Default value "Local".
+		TimeZone string `json:"time_zone" yaml:"time_zone"`
+
+		// DurationUnit is the unit of processing_time: "s", "ms", "us" or "ns".
+		// Optional. Default value "ms".
+		DurationUnit string `json:"duration_unit" yaml:"duration_unit"`
+
+		Service   ServiceConfig   `json:"service" yaml:"service"`
+		Redaction RedactionConfig `json:"redaction" yaml:"redaction"`
+		Caller    CallerOptions   `json:"caller" yaml:"caller"`
+
+		// Sampling enables sampling when set.
+		// Optional. Default value nil.
+		Sampling *SamplingOptions `json:"sampling" yaml:"sampling"`
+	}
+
+	// ServiceConfig overrides the service metadata resolved by appname.GetServiceInfo.
+	ServiceConfig struct {
+		Name        string `json:"name" yaml:"name"`
+		Version     string `json:"version" yaml:"version"`
+		Environment string `json:"environment" yaml:"environment"`
+	}
+
+	// RedactionConfig defines the values hidden from the logs.
+	RedactionConfig struct {
+		// Keys are data keys, case insensitive, whose values are logged as "**hidden**" (e.g. "password").
+		Keys []string `json:"keys" yaml:"keys"`
+
+		// Headers replace http.HiddenHeaders when set.
+		Headers []string `json:"headers" yaml:"headers"`
+	}
+
+	// CallerOptions is the file representation of CallerConfig.
+	CallerOptions struct {
+		Disable bool `json:"disable" yaml:"disable"`
+		// Path is "full", "module" or "short".
+		Path     string `json:"path" yaml:"path"`
+		Function bool   `json:"function" yaml:"function"`
+	}
+
+	// SamplingOptions is the file representation of SamplingConfig.
+	SamplingOptions struct {
+		Interval        Duration           `json:"interval" yaml:"interval"`
+		First           int                `json:"first" yaml:"first"`
+		Thereafter      int                `json:"thereafter" yaml:"thereafter"`
+		TagRates        map[string]float64 `json:"tag_rates" yaml:"tag_rates"`
+		KeepLevel       string             `json:"keep_level" yaml:"keep_level"`
+		SummaryInterval Duration           `json:"summary_interval" yaml:"summary_interval"`
+	}
+
+	// Duration is a time.Duration written as a string such as "1s" or "500ms" in config files.
+	Duration time.Duration
+
+	settings struct {
+		level        Level
+		tagLevels    map[string]Level
+		format       string
+		timeLayout   string
+		location     *time.Location
+		durationUnit time.Duration
+		app          string
+		service      map[string]interface{}
+		redactKeys   map[string]bool
+	}
+)
+
+var (
+	// DefaultConfig is the config the logger starts with.
+	DefaultConfig = Config{
+		Level:        "info",
+		Outputs:      []string{"stdout"},
+		Format:       "json",
+		TimeFormat:   RFC3339NanoTimeFormat,
+		TimeZone:     "Local",
+		DurationUnit: "ms",
+	}
+
+	currentSettings atomic.Value
+	openedFiles     []*os.File
+	// appliedConfig is the config of the last Configure call, nil before the first one
+	appliedConfig *Config
+	configMutex   sync.Mutex
+)
+
+func init() {
+	currentSettings.Store(settings{
+		level:        InfoLevel,
+		format:       "json",
+		timeLayout:   time.RFC3339Nano,
+		location:     time.Local,
+		durationUnit: time.Millisecond,
+		app:          app,
+		service:      service,
+	})
+}
+
+func loadSettings() settings {
+	return currentSettings.Load().(settings)
+}
+
+// enabled reports whether entries of tag at level are written.
+func (s settings) enabled(tag string, level Level) bool {
+	min, ok := s.tagLevels[tag]
+	if !ok {
+		min = s.level
+	}
+	return level >= min || level == FatalLevel
+}
+
+// Configure validates config and applies it to the global logger, nothing is applied when it is invalid.
+// It is safe to call while logging. The outputs, caller, hidden headers and sampling sections are only
+// applied when they differ from the previous Configure call, so unchanged files stay open and the sampling
+// counters are kept.
+func Configure(config Config) error {
+	s, callerConfig, samplingConfig, err := config.build()
+	if err != nil {
+		return err
+	}
+
+	configMutex.Lock()
+	defer configMutex.Unlock()
+	previous := appliedConfig
+
+	if previous == nil || !reflect.DeepEqual(previous.Outputs, config.Outputs) {
+		output, files, err := openOutputs(config.Outputs)
+		if err != nil {
+			return err
+		}
+
+		SetOutput(output)
+		for _, f := range openedFiles {
+			f.Close()
+		}
+		openedFiles = files
+	}
+
+	currentSettings.Store(s)
+	if previous == nil || previous.Caller != config.Caller {
+		SetCallerConfig(callerConfig)
+	}
+	if config.Redaction.Headers != nil && (previous == nil || !reflect.DeepEqual(previous.Redaction.Headers, config.Redaction.Headers)) {
+		goLoggerHttp.SetHiddenHeaders(config.Redaction.Headers)
+	}
+	if previous == nil || !reflect.DeepEqual(previous.Sampling, config.Sampling) {
+		if samplingConfig != nil {
+			SetSampling(*samplingConfig)
+		} else {
+			DisableSampling()
+		}
+	}
+
+	applied := config
+	appliedConfig = &applied
+	return nil
+}
+
+// Validate reports every invalid value of config.
+func (config Config) Validate() error {
+	_, _, _, err := config.build()
+	return err
+}
+
+func (config Config) build() (settings, CallerConfig, *SamplingConfig, error) {
+	var errs []error
+	s := settings{
+		level:        InfoLevel,
+		format:       "json",
+		timeLayout:   time.RFC3339Nano,
+		location:     time.Local,
+		durationUnit: time.Millisecond,
+		app:          goLoggerAppName.GetAPPName(),
+		redactKeys:   make(map[string]bool),
+	}
+
+	if config.Level != "" {
+		level, err := ParseLevel(config.Level)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("level: %w", err))
+		}
+		s.level = level
+	}
+
+	s.tagLevels = make(map[string]Level, len(config.TagLevels))
+	for tag, name := range config.TagLevels {
+		level, err := ParseLevel(name)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("tag_levels.%s: %w", tag, err))
+		}
+		s.tagLevels[tag] = level
+	}
+
+	switch strings.ToLower(config.Format) {
+	case "", "json":
+	case "text":
+		s.format = "text"
+	default:
+		errs = append(errs, fmt.Errorf("format: unknown format %q, expected \"json\" or \"text\"", config.Format))
+	}
+
+	if layout, err := parseTimeFormat(config.TimeFormat); err != nil {
+		errs = append(errs, fmt.Errorf("time_format: %w", err))
+	} else {
+		s.timeLayout = layout
+	}
+	if config.TimeZone != "" {
+		location, err := parseTimeZone(config.TimeZone)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("time_zone: %w", err))
+		}
+		s.location = location
+	}
+	if config.DurationUnit != "" {
+		unit, err := parseDurationUnit(config.DurationUnit)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("duration_unit: %w", err))
+		}
+		s.durationUnit = unit
+	}
+
+	for _, output := range config.Outputs {
+		if strings.TrimSpace(output) == "" {
+			errs = append(errs, errors.New("outputs: empty output"))
+		}
+	}
+
+	info := goLoggerAppName.GetServiceInfo()
+	if config.Service.Name != "" {
+		info.Name = config.Service.Name
+		s.app = config.Service.Name
+	}
+	if config.Service.Version != "" {
+		info.Version = config.Service.Version
+	}
+	if config.Service.Environment != "" {
+		info.Environment = config.Service.Environment
+	}
+	s.service = info.Fields()
+
+	for _, key := range config.Redaction.Keys {
+		s.redactKeys[strings.ToLower(key)] = true
+	}
+
+	callerConfig := CallerConfig{Disable: config.Caller.Disable, Function: config.Caller.Function}
+	switch strings.ToLower(config.Caller.Path) {
+	case "", "full":
+		callerConfig.Path = FullCallerPath
+	case "module":
+		callerConfig.Path = ModuleCallerPath
+	case "short":
+		callerConfig.Path = ShortCallerPath
+	default:
+		errs = append(errs, fmt.Errorf("caller.path: unknown path %q, expected \"full\", \"module\" or \"short\"", config.Caller.Path))
+	}
+
+	var samplingConfig *SamplingConfig
+	if config.Sampling != nil {
+		samplingConfig = &SamplingConfig{
+			Interval:        time.Duration(config.Sampling.Interval),
+			First:           config.Sampling.First,
+			Thereafter:      config.Sampling.Thereafter,
+			TagRates:        config.Sampling.TagRates,
+			SummaryInterval: time.Duration(config.Sampling.SummaryInterval),
+		}
+		if config.Sampling.KeepLevel != "" {
+			level, err := ParseLevel(config.Sampling.KeepLevel)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("sampling.keep_level: %w", err))
+			}
+			samplingConfig.KeepLevel = level
+		}
+		if config.Sampling.First < 0 || config.Sampling.Thereafter < 0 {
+			errs = append(errs, errors.New("sampling: first and thereafter must not be negative"))
+		}
+		for tag, rate := range config.Sampling.TagRates {
+			if rate < 0 || rate > 1 {
+				errs = append(errs, fmt.Errorf("sampling.tag_rates.%s: rate %v is not between 0 and 1", tag, rate))
+			}
+		}
+	}
+
+	return s, callerConfig, samplingConfig, errors.Join(errs...)
+}
+
+func openOutputs(outputs []string) (io.Writer, []*os.File, error) {
+	if len(outputs) == 0 {
+		outputs = DefaultConfig.Outputs
+	}
+
+	var writers []io.Writer
+	var files []*os.File
+	for _, output := range outputs {
+		switch output {
+		case "stdout":
+			writers = append(writers, os.Stdout)
+		case "stderr":
+			writers = append(writers, os.Stderr)
+		default:
+			f, err := os.OpenFile(output, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+			if err != nil {
+				for _, opened := range files {
+					opened.Close()
+				}
+				return nil, nil, fmt.Errorf("outputs: %w", err)
+			}
+			writers = append(writers, f)
+			files = append(files, f)
+		}
+	}
+
+	if len(writers) == 1 {
+		return writers[0], files, nil
+	}
+	return io.MultiWriter(writers...), files, nil
+}
+
+// LoadConfig reads a YAML (.yaml, .yml) or JSON config file, the values missing from the file keep their defaults.
+func LoadConfig(path string) (Config, error) {
+	config := DefaultConfig
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return config, err
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(b, &config)
+	case ".json":
+		err = json.Unmarshal(b, &config)
+	default:
+		return config, fmt.Errorf("unsupported config file %s, expected .yaml, .yml or .json", path)
+	}
+	if err != nil {
+		return config, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+
+	return config, config.Validate()
+}
+
+// ConfigFromEnv returns base overridden by the GOLOGGER_* environment variables:
+// GOLOGGER_LEVEL, GOLOGGER_OUTPUTS (comma separated), GOLOGGER_FORMAT, GOLOGGER_TIME_FORMAT, GOLOGGER_TIME_ZONE, GOLOGGER_DURATION_UNIT,
+// GOLOGGER_SERVICE_NAME, GOLOGGER_SERVICE_VERSION, GOLOGGER_SERVICE_ENVIRONMENT, GOLOGGER_REDACT_KEYS,
+// GOLOGGER_REDACT_HEADERS, GOLOGGER_CALLER_DISABLE, GOLOGGER_CALLER_PATH, GOLOGGER_CALLER_FUNCTION,
+// GOLOGGER_SAMPLING_INTERVAL, GOLOGGER_SAMPLING_FIRST and GOLOGGER_SAMPLING_THEREAFTER.
+func ConfigFromEnv(base Config) (Config, error) {
+	config := base
+	var errs []error
+
+	str := func(key string, target *string) {
+		if v, ok := os.LookupEnv(key); ok {
+			*target = v
+		}
+	}
+	list := func(key string, target *[]string) {
+		if v, ok := os.LookupEnv(key); ok {
+			*target = splitList(v)
+		}
+	}
+	boolean := func(key string, target *bool) {
+		if v, ok := os.LookupEnv(key); ok {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", key, err))
+			}
+			*target = b
+		}
+	}
+	sampling := func() *SamplingOptions {
+		if config.Sampling == nil {
+			config.Sampling = &SamplingOptions{}
+		} else {
+			copied := *config.Sampling
+			config.Sampling = &copied
+		}
+		return config.Sampling
+	}
+
+	str("GOLOGGER_LEVEL", &config.Level)
+	list("GOLOGGER_OUTPUTS", &config.Outputs)
+	str("GOLOGGER_FORMAT", &config.Format)
+	str("GOLOGGER_TIME_FORMAT", &config.TimeFormat)
+	str("GOLOGGER_TIME_ZONE", &config.TimeZone)
+	str("GOLOGGER_DURATION_UNIT", &config.DurationUnit)
+	str("GOLOGGER_SERVICE_NAME", &config.Service.Name)
+	str("GOLOGGER_SERVICE_VERSION", &config.Service.Version)
+	str("GOLOGGER_SERVICE_ENVIRONMENT", &config.Service.Environment)
+	list("GOLOGGER_REDACT_KEYS", &config.Redaction.Keys)
+	list("GOLOGGER_REDACT_HEADERS", &config.Redaction.Headers)
+	boolean("GOLOGGER_CALLER_DISABLE", &config.Caller.Disable)
+	str("GOLOGGER_CALLER_PATH", &config.Caller.Path)
+	boolean("GOLOGGER_CALLER_FUNCTION", &config.Caller.Function)
+
+	if v, ok := os.LookupEnv("GOLOGGER_SAMPLING_INTERVAL"); ok {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("GOLOGGER_SAMPLING_INTERVAL: %w", err))
+		}
+		sampling().Interval = Duration(d)
+	}
+	for key, target := range map[string]func() *int{
+		"GOLOGGER_SAMPLING_FIRST":      func() *int { return &sampling().First },
+		"GOLOGGER_SAMPLING_THEREAFTER": func() *int { return &sampling().Thereafter },
+	} {
+		if v, ok := os.LookupEnv(key); ok {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", key, err))
+			}
+			*target() = n
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return config, err
+	}
+	return config, config.Validate()
+}
+
+func splitList(s string) []string {
+	list := []string{}
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
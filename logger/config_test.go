@@ -0,0 +1,208 @@
+package logger
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	goLoggerHttp "github.com/pobyzaarif/go-logger/http"
+)
+
+func TestConfigureWhileLogging(t *testing.T) {
+	ReplaceGlobalsForTest(t)
+	dir := t.TempDir()
+
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			log := NewLog("TEST")
+			for {
+				select {
+				case <-done:
+					return
+				default:
+					log.InfoWithData("message", map[string]interface{}{"password": "secret"})
+					goLoggerHttp.HiddenHeaders()
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < 50; i++ {
+		config := DefaultConfig
+		config.Outputs = []string{filepath.Join(dir, "a.log")}
+		config.Redaction.Headers = []string{"Authorization"}
+		if i%2 == 1 {
+			config.Format = "text"
+			config.Outputs = []string{filepath.Join(dir, "b.log")}
+			config.Redaction.Headers = []string{"Authorization", "Cookie"}
+		}
+		if err := Configure(config); err != nil {
+			t.Fatal(err)
+		}
+	}
+	close(done)
+	wg.Wait()
+}
+
+func TestConfigureKeepsUnchangedSections(t *testing.T) {
+	ReplaceGlobalsForTest(t)
+	config := DefaultConfig
+	config.Outputs = []string{filepath.Join(t.TempDir(), "out.log")}
+	config.Sampling = &SamplingOptions{First: 1, Thereafter: 10}
+	if err := Configure(config); err != nil {
+		t.Fatal(err)
+	}
+
+	output := Output()
+	samplerMutex.RLock()
+	previousSampler := sampler
+	samplerMutex.RUnlock()
+
+	config.Level = "warn"
+	if err := Configure(config); err != nil {
+		t.Fatal(err)
+	}
+
+	if Output() != output {
+		t.Error("output was reopened although outputs did not change")
+	}
+	samplerMutex.RLock()
+	defer samplerMutex.RUnlock()
+	if sampler != previousSampler {
+		t.Error("sampler was replaced although sampling did not change")
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	files := map[string]string{
+		"gologger.yaml": `
+level: warn
+tag_levels:
+  MONGO_QUERY: error
+time_format: unix_ms
+redaction:
+  keys: [password]
+sampling:
+  interval: 500ms
+  first: 10
+  summary_interval: 1m
+`,
+		"gologger.json": `{
+  "level": "warn",
+  "tag_levels": {"MONGO_QUERY": "error"},
+  "time_format": "unix_ms",
+  "redaction": {"keys": ["password"]},
+  "sampling": {"interval": "500ms", "first": 10, "summary_interval": "1m"}
+}`,
+	}
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			if err := ioutil.WriteFile(path, []byte(content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			config, err := LoadConfig(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := DefaultConfig
+			want.Level = "warn"
+			want.TagLevels = map[string]string{"MONGO_QUERY": "error"}
+			want.TimeFormat = UnixMilliTimeFormat
+			want.Redaction.Keys = []string{"password"}
+			want.Sampling = &SamplingOptions{
+				Interval:        Duration(500 * time.Millisecond),
+				First:           10,
+				SummaryInterval: Duration(time.Minute),
+			}
+			if !reflect.DeepEqual(config, want) {
+				t.Errorf("got config %+v, want %+v", config, want)
+			}
+		})
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"gologger.toml":     `level = "warn"`,
+		"duration.yaml":     "sampling:\n  interval: often\n",
+		"duration.json":     `{"sampling": {"interval": 500}}`,
+		"invalid.json":      `{"level": `,
+		"unknown_level.yml": "level: verbose\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadConfig(path); err == nil {
+			t.Errorf("%s: got no error", name)
+		}
+	}
+
+	if _, err := LoadConfig(filepath.Join(dir, "missing.yaml")); err == nil {
+		t.Error("got no error for a missing file")
+	}
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("GOLOGGER_LEVEL", "error")
+	t.Setenv("GOLOGGER_OUTPUTS", "stdout, stderr,")
+	t.Setenv("GOLOGGER_REDACT_KEYS", "password,token")
+	t.Setenv("GOLOGGER_CALLER_FUNCTION", "true")
+	t.Setenv("GOLOGGER_SAMPLING_INTERVAL", "2s")
+	t.Setenv("GOLOGGER_SAMPLING_THEREAFTER", "100")
+
+	base := DefaultConfig
+	base.Sampling = &SamplingOptions{First: 5}
+	config, err := ConfigFromEnv(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := DefaultConfig
+	want.Level = "error"
+	want.Outputs = []string{"stdout", "stderr"}
+	want.Redaction.Keys = []string{"password", "token"}
+	want.Caller.Function = true
+	want.Sampling = &SamplingOptions{First: 5, Thereafter: 100, Interval: Duration(2 * time.Second)}
+	if !reflect.DeepEqual(config, want) {
+		t.Errorf("got config %+v, want %+v", config, want)
+	}
+	if base.Sampling.Thereafter != 0 {
+		t.Error("the sampling options of the base config were modified")
+	}
+}
+
+func TestConfigFromEnvErrors(t *testing.T) {
+	tests := []struct {
+		key, value string
+		// parse errors name the variable, validation errors name the value
+		want string
+	}{
+		{"GOLOGGER_CALLER_DISABLE", "maybe", "GOLOGGER_CALLER_DISABLE"},
+		{"GOLOGGER_SAMPLING_INTERVAL", "often", "GOLOGGER_SAMPLING_INTERVAL"},
+		{"GOLOGGER_SAMPLING_FIRST", "ten", "GOLOGGER_SAMPLING_FIRST"},
+		{"GOLOGGER_SAMPLING_THEREAFTER", "1.5", "GOLOGGER_SAMPLING_THEREAFTER"},
+		{"GOLOGGER_LEVEL", "verbose", "verbose"},
+		{"GOLOGGER_DURATION_UNIT", "minutes", "minutes"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			t.Setenv(tt.key, tt.value)
+			if _, err := ConfigFromEnv(DefaultConfig); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got error %v, want it to name %s", err, tt.want)
+			}
+		})
+	}
+}
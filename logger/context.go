@@ -0,0 +1,67 @@
+package logger
+
+import (
+	"context"
+	"sync"
+)
+
+type (
+	// ContextExtractor returns fields read from ctx, stamped as top level fields on the log lines
+	// of a logger set with SetContext, e.g. the trace_id and span_id of a tracing library.
+	ContextExtractor func(ctx context.Context) map[string]interface{}
+
+	registeredContextExtractor struct {
+		id        int
+		extractor ContextExtractor
+	}
+)
+
+var (
+	contextExtractors      []registeredContextExtractor
+	contextExtractorsID    int
+	contextExtractorsMutex sync.RWMutex
+)
+
+// AddContextExtractor appends extractor to the extractors run by SetContext, remove unregisters it.
+// Fields of later extractors replace the ones of earlier extractors, a span_id field replaces
+// the span_id and parent_span_id of the go-logger span.
+func AddContextExtractor(extractor ContextExtractor) (remove func()) {
+	contextExtractorsMutex.Lock()
+	defer contextExtractorsMutex.Unlock()
+
+	contextExtractorsID++
+	id := contextExtractorsID
+	// copy on write, contextFields iterates without holding the lock
+	contextExtractors = append(append([]registeredContextExtractor{}, contextExtractors...), registeredContextExtractor{id: id, extractor: extractor})
+
+	return func() {
+		contextExtractorsMutex.Lock()
+		defer contextExtractorsMutex.Unlock()
+
+		remaining := make([]registeredContextExtractor, 0, len(contextExtractors))
+		for _, e := range contextExtractors {
+			if e.id != id {
+				remaining = append(remaining, e)
+			}
+		}
+		contextExtractors = remaining
+	}
+}
+
+// contextFields runs the extractors on ctx, nil when none returned a field.
+func contextFields(ctx context.Context) map[string]interface{} {
+	contextExtractorsMutex.RLock()
+	extractors := contextExtractors
+	contextExtractorsMutex.RUnlock()
+
+	var fields map[string]interface{}
+	for _, e := range extractors {
+		for k, v := range e.extractor(ctx) {
+			if fields == nil {
+				fields = make(map[string]interface{})
+			}
+			fields[k] = v
+		}
+	}
+	return fields
+}
@@ -0,0 +1,188 @@
+package logger
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+type (
+	// DedupConfig defines how identical entries are collapsed.
+	DedupConfig struct {
+		// Window is how long identical (tag, level, message, error) entries are collapsed after the first one.
+		// Optional. Default value 1 second.
+		Window time.Duration
+
+		// MaxTrackerIDs is the number of distinct tracker IDs kept as a sample in the collapsed entry.
+		// Optional. Default value 5.
+		MaxTrackerIDs int
+
+		// MaxKeys is the number of distinct entries tracked at once, new entries are written as is beyond it.
+		// Optional. Default value 10000.
+		MaxKeys int
+	}
+
+	dedupGroup struct {
+		level      Level
+		logParams  map[string]interface{}
+		count      int
+		firstSeen  time.Time
+		lastSeen   time.Time
+		trackerIDs []string
+	}
+
+	dedup struct {
+		config DedupConfig
+		groups map[string]*dedupGroup
+		stop   chan struct{}
+		mutex  sync.Mutex
+	}
+)
+
+var (
+	// DefaultDedupConfig is the default deduplication config.
+	DefaultDedupConfig = DedupConfig{
+		Window:        time.Second,
+		MaxTrackerIDs: 5,
+		MaxKeys:       10000,
+	}
+
+	deduplicator      *dedup
+	deduplicatorMutex sync.RWMutex
+)
+
+// SetDedup writes the first of identical entries logged within a window and collapses the following ones
+// into a single entry with repeat_count, first_seen, last_seen and a sample of their tracker_ids.
+func SetDedup(config DedupConfig) {
+	if config.Window <= 0 {
+		config.Window = DefaultDedupConfig.Window
+	}
+	if config.MaxTrackerIDs <= 0 {
+		config.MaxTrackerIDs = DefaultDedupConfig.MaxTrackerIDs
+	}
+	if config.MaxKeys <= 0 {
+		config.MaxKeys = DefaultDedupConfig.MaxKeys
+	}
+
+	d := &dedup{
+		config: config,
+		groups: make(map[string]*dedupGroup),
+		stop:   make(chan struct{}),
+	}
+	go d.run()
+
+	replaceDeduplicator(d)
+}
+
+// DisableDedup writes the pending collapsed entries and stops deduplication.
+func DisableDedup() {
+	replaceDeduplicator(nil)
+}
+
+func replaceDeduplicator(d *dedup) {
+	deduplicatorMutex.Lock()
+	previous := deduplicator
+	deduplicator = d
+	deduplicatorMutex.Unlock()
+
+	if previous != nil {
+		close(previous.stop)
+		previous.flush(true)
+	}
+}
+
+// deduplicate reports whether the entry is a repeat collapsed by the deduplication stage.
+func deduplicate(level Level, logParams map[string]interface{}) bool {
+	deduplicatorMutex.RLock()
+	d := deduplicator
+	deduplicatorMutex.RUnlock()
+	if d == nil || level == FatalLevel {
+		return false
+	}
+
+	key := fmt.Sprintf("%v|%s|%v|%v", logParams["tag"], level, logParams["message"], logParams["error"])
+	now := time.Now()
+	trackerID, _ := logParams["tracker_id"].(string)
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	group, ok := d.groups[key]
+	if !ok {
+		if len(d.groups) < d.config.MaxKeys {
+			group = &dedupGroup{firstSeen: now, lastSeen: now}
+			// the first entry is written as is, its tracker ID still belongs to the sample
+			if trackerID != "" {
+				group.trackerIDs = []string{trackerID}
+			}
+			d.groups[key] = group
+		}
+		return false
+	}
+
+	group.level = level
+	group.logParams = logParams
+	group.count++
+	group.lastSeen = now
+	if trackerID != "" && len(group.trackerIDs) < d.config.MaxTrackerIDs && !contains(group.trackerIDs, trackerID) {
+		group.trackerIDs = append(group.trackerIDs, trackerID)
+	}
+
+	return true
+}
+
+func (d *dedup) run() {
+	interval := d.config.Window / 4
+	if interval < 10*time.Millisecond {
+		interval = 10 * time.Millisecond
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			d.flush(false)
+		case <-d.stop:
+			return
+		}
+	}
+}
+
+// flush writes one collapsed entry per group whose window ended, or every group when all is set.
+func (d *dedup) flush(all bool) {
+	var collapsed []*dedupGroup
+
+	d.mutex.Lock()
+	now := time.Now()
+	for key, group := range d.groups {
+		if all || now.Sub(group.firstSeen) >= d.config.Window {
+			delete(d.groups, key)
+			if group.count > 0 {
+				collapsed = append(collapsed, group)
+			}
+		}
+	}
+	d.mutex.Unlock()
+
+	for _, group := range collapsed {
+		logParams := make(map[string]interface{}, len(group.logParams)+4)
+		for k, v := range group.logParams {
+			logParams[k] = v
+		}
+		logParams["repeat_count"] = group.count
+		logParams["first_seen"] = loadSettings().formatTime(group.firstSeen)
+		logParams["last_seen"] = loadSettings().formatTime(group.lastSeen)
+		logParams["tracker_ids"] = group.trackerIDs
+		emit(group.level, logParams)
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
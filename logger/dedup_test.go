@@ -0,0 +1,32 @@
+package logger
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDedupSamplesFirstTrackerID(t *testing.T) {
+	observer := ReplaceGlobalsForTest(t)
+	SetDedup(DedupConfig{Window: time.Hour})
+
+	log := NewLog("TEST")
+	for _, trackerID := range []string{"first", "second", "second", "third"} {
+		log.SetTrackerID(trackerID)
+		log.Warn("repeated")
+	}
+	DisableDedup()
+
+	entries := observer.All()
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want the first one and the collapsed one", len(entries))
+	}
+	collapsed := entries[1].Fields
+	if collapsed["repeat_count"] != float64(3) {
+		t.Errorf("got repeat_count %v, want 3", collapsed["repeat_count"])
+	}
+	want := []interface{}{"first", "second", "third"}
+	if !reflect.DeepEqual(collapsed["tracker_ids"], want) {
+		t.Errorf("got tracker_ids %v, want %v", collapsed["tracker_ids"], want)
+	}
+}
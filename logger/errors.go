@@ -0,0 +1,214 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+
+	pkgErrors "github.com/pkg/errors"
+)
+
+// FieldsError is implemented by errors carrying structured log context, e.g. *errors.Error of the errors
+// subpackage, whose fields are logged in the "error_fields" section.
+type FieldsError interface {
+	error
+	LogFields() map[string]interface{}
+}
+
+// ErrorConfig defines how errors are expanded in the "error_detail" section.
+type ErrorConfig struct {
+	// DisableDetail disables the "error_detail" section, only the "error" message is logged.
+	// Optional. Default value false.
+	DisableDetail bool
+
+	// CaptureStack logs the stack of the logging goroutine for entries at or above ErrorLevel
+	// whose error does not carry a stack trace.
+	// Optional. Default value false.
+	CaptureStack bool
+
+	// StackDepth is the maximum number of stack frames logged.
+	// Optional. Default value 32.
+	StackDepth int
+
+	// MaxChain is the maximum number of errors logged from the wrapped chain.
+	// Optional. Default value 10.
+	MaxChain int
+}
+
+var (
+	// DefaultErrorConfig is the default error config.
+	DefaultErrorConfig = ErrorConfig{
+		DisableDetail: false,
+		CaptureStack:  false,
+		StackDepth:    32,
+		MaxChain:      10,
+	}
+
+	errorConfig      = DefaultErrorConfig
+	errorConfigMutex sync.RWMutex
+)
+
+// SetErrorConfig replaces the error config.
+func SetErrorConfig(config ErrorConfig) {
+	if config.StackDepth <= 0 {
+		config.StackDepth = DefaultErrorConfig.StackDepth
+	}
+	if config.MaxChain <= 0 {
+		config.MaxChain = DefaultErrorConfig.MaxChain
+	}
+
+	errorConfigMutex.Lock()
+	errorConfig = config
+	errorConfigMutex.Unlock()
+}
+
+func currentErrorConfig() ErrorConfig {
+	errorConfigMutex.RLock()
+	defer errorConfigMutex.RUnlock()
+	return errorConfig
+}
+
+// errorDetail returns the type, the wrapped chain and the stack trace of err.
+func errorDetail(level Level, err error) map[string]interface{} {
+	config := currentErrorConfig()
+	if err == nil || config.DisableDetail {
+		return nil
+	}
+
+	chain := make([]map[string]interface{}, 0)
+	for _, e := range errorChain(err, config.MaxChain) {
+		chain = append(chain, map[string]interface{}{
+			"type":    fmt.Sprintf("%T", e),
+			"message": e.Error(),
+		})
+	}
+
+	detail := map[string]interface{}{
+		"type":  fmt.Sprintf("%T", err),
+		"chain": chain,
+	}
+
+	if pcs := errorStack(err); len(pcs) > 0 {
+		detail["stack"] = formatStack(pcs, config.StackDepth)
+		detail["stack_source"] = "error"
+	} else if config.CaptureStack && level >= ErrorLevel {
+		detail["stack"] = formatStack(callerPCs(config.StackDepth), config.StackDepth)
+		detail["stack_source"] = "logger"
+	}
+
+	return detail
+}
+
+// errorFields returns the fields of the first FieldsError in the chain of err.
+func errorFields(err error) map[string]interface{} {
+	var fieldsError FieldsError
+	if errors.As(err, &fieldsError) {
+		return fieldsError.LogFields()
+	}
+	return nil
+}
+
+// errorChain flattens err and the errors it wraps, through Unwrap() error and Unwrap() []error (errors.Join), depth first.
+func errorChain(err error, max int) []error {
+	chain := []error{}
+	var walk func(error)
+	walk = func(e error) {
+		if e == nil || len(chain) >= max {
+			return
+		}
+		chain = append(chain, e)
+		switch wrapped := e.(type) {
+		case interface{ Unwrap() []error }:
+			for _, child := range wrapped.Unwrap() {
+				walk(child)
+			}
+		default:
+			walk(errors.Unwrap(e))
+		}
+	}
+	walk(err)
+	return chain
+}
+
+type (
+	// stackTracer is an error recording its stack as program counters, e.g. *errors.Error of the errors subpackage.
+	stackTracer interface {
+		StackTrace() []uintptr
+	}
+
+	// pkgStackTracer is an error of github.com/pkg/errors.
+	pkgStackTracer interface {
+		StackTrace() pkgErrors.StackTrace
+	}
+)
+
+// errorStack returns the program counters of the deepest error of the chain carrying a stack trace.
+func errorStack(err error) []uintptr {
+	var pcs []uintptr
+	for _, e := range errorChain(err, 100) {
+		var frames []uintptr
+		switch tracer := e.(type) {
+		case stackTracer:
+			frames = tracer.StackTrace()
+		case pkgStackTracer:
+			stack := tracer.StackTrace()
+			frames = make([]uintptr, len(stack))
+			for i, frame := range stack {
+				frames[i] = uintptr(frame)
+			}
+		}
+		if len(frames) > 0 {
+			pcs = frames
+		}
+	}
+	return pcs
+}
+
+// callerPCs returns the program counters of the logging goroutine outside of this package.
+func callerPCs(depth int) []uintptr {
+	pcs := make([]uintptr, depth+16)
+	pcs = pcs[:runtime.Callers(2, pcs)]
+
+	frames := runtime.CallersFrames(pcs)
+	for i := 0; ; i++ {
+		frame, more := frames.Next()
+		if !isOwnFrame(frame.Function) {
+			return pcs[i:]
+		}
+		if !more {
+			return nil
+		}
+	}
+}
+
+func formatStack(pcs []uintptr, depth int) []string {
+	stack := make([]string, 0, depth)
+	frames := runtime.CallersFrames(pcs)
+	for len(stack) < depth {
+		frame, more := frames.Next()
+		if frame.Function != "" {
+			stack = append(stack, fmt.Sprintf("%s %s:%d", frame.Function, frame.File, frame.Line))
+		}
+		if !more {
+			break
+		}
+	}
+	return stack
+}
+
+// stackFunctions returns the functions of the first n frames of pcs.
+func stackFunctions(pcs []uintptr, n int) []string {
+	functions := make([]string, 0, n)
+	frames := runtime.CallersFrames(pcs)
+	for len(functions) < n {
+		frame, more := frames.Next()
+		if frame.Function != "" {
+			functions = append(functions, frame.Function)
+		}
+		if !more {
+			break
+		}
+	}
+	return functions
+}
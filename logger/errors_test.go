@@ -0,0 +1,73 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	pkgErrors "github.com/pkg/errors"
+)
+
+func TestErrorChain(t *testing.T) {
+	first, second, third := errors.New("first"), errors.New("second"), errors.New("third")
+	err := fmt.Errorf("wrapped: %w", errors.Join(first, fmt.Errorf("second: %w", second), third))
+
+	var messages []string
+	for _, e := range errorChain(err, 10) {
+		messages = append(messages, e.Error())
+	}
+	want := []string{err.Error(), errors.Join(first, fmt.Errorf("second: %w", second), third).Error(), "first", "second: second", "second", "third"}
+	if strings.Join(messages, "|") != strings.Join(want, "|") {
+		t.Errorf("got chain %q, want %q", messages, want)
+	}
+
+	if n := len(errorChain(err, 3)); n != 3 {
+		t.Errorf("got %d errors with max 3", n)
+	}
+}
+
+func TestErrorStack(t *testing.T) {
+	tests := map[string]error{
+		"[]uintptr":  fmt.Errorf("charge: %w", newStackError("card declined")),
+		"pkg/errors": pkgErrors.Wrap(pkgErrors.New("card declined"), "charge"),
+	}
+	for name, err := range tests {
+		t.Run(name, func(t *testing.T) {
+			pcs := errorStack(err)
+			if len(pcs) == 0 {
+				t.Fatal("got no stack")
+			}
+			stack := formatStack(pcs, 1)
+			if len(stack) != 1 || !strings.HasPrefix(stack[0], packagePath+".TestErrorStack ") || !strings.Contains(stack[0], "errors_test.go:") {
+				t.Errorf("got stack %q, want the frame of TestErrorStack", stack)
+			}
+		})
+	}
+
+	if pcs := errorStack(errors.New("no stack")); pcs != nil {
+		t.Errorf("got stack %v for an error without one", pcs)
+	}
+}
+
+func TestErrorDetailCapturesLoggerStack(t *testing.T) {
+	observer := ReplaceGlobalsForTest(t)
+	SetErrorConfig(ErrorConfig{CaptureStack: true, StackDepth: 2})
+
+	log := NewLog("TEST")
+	log.Warn("warning")
+	log.Error("failed", errors.New("no stack"))
+
+	if _, ok := observer.FilterLevel("WARN").All()[0].Fields["error_detail"]; ok {
+		t.Error("got error_detail on an entry without error")
+	}
+	detail := observer.FilterLevel("ERROR").All()[0].Fields["error_detail"].(map[string]interface{})
+	stack := detail["stack"].([]interface{})
+	if detail["stack_source"] != "logger" || len(stack) != 2 {
+		t.Fatalf("got error_detail %v, want 2 frames captured by the logger", detail)
+	}
+	// the frames of this package, the tests included, are skipped
+	if frame := stack[0].(string); !strings.HasPrefix(frame, "testing.tRunner ") {
+		t.Errorf("got first frame %q, want the first one outside of the logger package", frame)
+	}
+}
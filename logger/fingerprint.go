@@ -0,0 +1,80 @@
+package logger
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+// number of stack frames part of a fingerprint
+const fingerprintFrames = 3
+
+var (
+	uuidPattern   = regexp.MustCompile(`(?i)\b[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\b`)
+	hexIDPattern  = regexp.MustCompile(`(?i)\b(0x)?[0-9a-f]{8,}\b`)
+	numberPattern = regexp.MustCompile(`\d+(\.\d+)?`)
+	quotedPattern = regexp.MustCompile(`"[^"]*"|'[^']*'`)
+)
+
+// Fingerprint returns a stable identifier of an error entry, built from the tag, the normalized
+// message and error, the type of the innermost error and the functions of the top stack frames.
+func Fingerprint(tag, message string, err error, frames []string) string {
+	errType, errMessage := "", ""
+	if err != nil {
+		root := err
+		for unwrapped := errors.Unwrap(root); unwrapped != nil; unwrapped = errors.Unwrap(root) {
+			root = unwrapped
+		}
+		errType = fmt.Sprintf("%T", root)
+		errMessage = NormalizeMessage(err.Error())
+	}
+
+	h := sha1.New()
+	for _, part := range append([]string{tag, NormalizeMessage(message), errType, errMessage}, frames...) {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// NormalizeMessage replaces the variable parts of a message (quoted values, UUIDs, hexadecimal IDs and numbers) with placeholders.
+func NormalizeMessage(message string) string {
+	message = quotedPattern.ReplaceAllString(message, `"?"`)
+	message = uuidPattern.ReplaceAllString(message, "<uuid>")
+	message = hexIDPattern.ReplaceAllString(message, "<id>")
+	return numberPattern.ReplaceAllString(message, "<n>")
+}
+
+// errorFrames returns the functions of the top frames of the stack carried by err, or of the logging goroutine.
+func errorFrames(err error, n int) []string {
+	if pcs := errorStack(err); len(pcs) > 0 {
+		return stackFunctions(pcs, n)
+	}
+	return callerFrames(n)
+}
+
+// callerFrames returns the functions of the top stack frames outside of this package.
+func callerFrames(n int) []string {
+	pcs := make([]uintptr, n+16)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+
+	functions := make([]string, 0, n)
+	for len(functions) < n {
+		frame, more := frames.Next()
+		if frame.Function != "" && !isOwnFrame(frame.Function) && !strings.HasPrefix(frame.Function, "runtime.") {
+			functions = append(functions, frame.Function)
+		}
+		if !more {
+			break
+		}
+	}
+	return functions
+}
+
+func isOwnFrame(function string) bool {
+	return strings.HasPrefix(function, packagePath+".")
+}
@@ -0,0 +1,22 @@
+package logger
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFingerprintOnlyOnErrorEntries(t *testing.T) {
+	observer := ReplaceGlobalsForTest(t)
+
+	log := NewLog("TEST")
+	err := errors.New("connection refused")
+	log.WarnWithDataAndError("retrying", nil, err)
+	log.Error("failed", err)
+
+	if fingerprint := observer.FilterLevel("WARN").All()[0].Fields["error_fingerprint"]; fingerprint != nil {
+		t.Errorf("warn entry has error_fingerprint %v", fingerprint)
+	}
+	if fingerprint := observer.FilterLevel("ERROR").All()[0].Fields["error_fingerprint"]; fingerprint == nil {
+		t.Error("error entry has no error_fingerprint")
+	}
+}
@@ -0,0 +1,76 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// fields written first, in this order, by the text format
+var textFields = []string{"time", "tag", "message", "tracker_id", "error", "caller"}
+
+// emitText writes logParams as `LEVEL time [tag] message key=value ...`.
+func emitText(level Level, logParams map[string]interface{}) {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%v [%v] %v", logParams["time"], logParams["tag"], logParams["message"])
+
+	written := map[string]bool{"time": true, "tag": true, "message": true}
+	for _, key := range textFields {
+		if !written[key] {
+			writeTextField(&sb, key, logParams[key])
+			written[key] = true
+		}
+	}
+
+	keys := make([]string, 0, len(logParams))
+	for key := range logParams {
+		if !written[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		writeTextField(&sb, key, logParams[key])
+	}
+
+	switch level {
+	case InfoLevel:
+		textLogger.Info(sb.String())
+	case WarnLevel:
+		textLogger.Warn(sb.String())
+	case ErrorLevel:
+		textLogger.Error(sb.String())
+	case FatalLevel:
+		textLogger.Fatal(sb.String())
+	}
+}
+
+func writeTextField(sb *strings.Builder, key string, value interface{}) {
+	switch v := value.(type) {
+	case nil:
+		return
+	case string:
+		if v == "" {
+			return
+		}
+		if strings.ContainsAny(v, " \t\n\"=") {
+			fmt.Fprintf(sb, " %s=%q", key, v)
+		} else {
+			fmt.Fprintf(sb, " %s=%s", key, v)
+		}
+	case map[string]interface{}:
+		if len(v) == 0 {
+			return
+		}
+		b, _ := json.Marshal(v)
+		fmt.Fprintf(sb, " %s=%s", key, b)
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			fmt.Fprintf(sb, " %s=%v", key, v)
+			return
+		}
+		fmt.Fprintf(sb, " %s=%s", key, b)
+	}
+}
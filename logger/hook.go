@@ -0,0 +1,139 @@
+package logger
+
+import (
+	"sync"
+	"time"
+)
+
+type (
+	// Entry is a log entry passed to hooks before it is encoded.
+	Entry struct {
+		Level     Level
+		Tag       string
+		Message   string
+		TrackerID string
+		// Data is the "data" section, e.g. {"app": {...}} or {"net": {...}}.
+		Data  map[string]interface{}
+		Error error
+		// Fingerprint groups recurring errors, set when Error is not nil and Level is Error or Fatal, see Fingerprint.
+		Fingerprint string
+		// Fields are extra top level fields added to the entry.
+		Fields map[string]interface{}
+		// Duration is the processing_time of the entry, zero when no timer was started.
+		Duration time.Duration
+	}
+
+	// Hook receives each entry before it is encoded and may change it, returning false drops the entry.
+	// Fatal entries cannot be dropped.
+	Hook func(entry *Entry) bool
+
+	registeredHook struct {
+		id   int
+		hook Hook
+	}
+
+	// Listener receives each entry before the level check and the hook chain, so it sees every entry
+	// whatever the verbosity, e.g. to derive metrics. It must not change the entry.
+	Listener func(entry Entry)
+
+	registeredListener struct {
+		id       int
+		listener Listener
+	}
+)
+
+var (
+	hooks      []registeredHook
+	hooksID    int
+	hooksMutex sync.RWMutex
+
+	listeners      []registeredListener
+	listenersID    int
+	listenersMutex sync.RWMutex
+)
+
+// AddHook appends hook to the chain run on every entry, remove unregisters it.
+func AddHook(hook Hook) (remove func()) {
+	hooksMutex.Lock()
+	defer hooksMutex.Unlock()
+
+	hooksID++
+	id := hooksID
+	// copy on write, runHooks iterates without holding the lock
+	hooks = append(append([]registeredHook{}, hooks...), registeredHook{id: id, hook: hook})
+
+	return func() {
+		hooksMutex.Lock()
+		defer hooksMutex.Unlock()
+
+		remaining := make([]registeredHook, 0, len(hooks))
+		for _, h := range hooks {
+			if h.id != id {
+				remaining = append(remaining, h)
+			}
+		}
+		hooks = remaining
+	}
+}
+
+// ClearHooks unregisters every hook.
+func ClearHooks() {
+	hooksMutex.Lock()
+	hooks = nil
+	hooksMutex.Unlock()
+}
+
+// runHooks runs the hook chain on entry, it stops and returns false as soon as a hook drops the entry.
+func runHooks(entry *Entry) bool {
+	hooksMutex.RLock()
+	chain := hooks
+	hooksMutex.RUnlock()
+
+	for _, h := range chain {
+		if !h.hook(entry) {
+			return false
+		}
+	}
+	return true
+}
+
+// AddListener appends listener to the listeners run on every entry, remove unregisters it.
+func AddListener(listener Listener) (remove func()) {
+	listenersMutex.Lock()
+	defer listenersMutex.Unlock()
+
+	listenersID++
+	id := listenersID
+	// copy on write, runListeners iterates without holding the lock
+	listeners = append(append([]registeredListener{}, listeners...), registeredListener{id: id, listener: listener})
+
+	return func() {
+		listenersMutex.Lock()
+		defer listenersMutex.Unlock()
+
+		remaining := make([]registeredListener, 0, len(listeners))
+		for _, l := range listeners {
+			if l.id != id {
+				remaining = append(remaining, l)
+			}
+		}
+		listeners = remaining
+	}
+}
+
+// Listening reports whether a listener is registered, so the data of an entry only passed to Notify is built when needed.
+func Listening() bool {
+	return len(currentListeners()) > 0
+}
+
+func currentListeners() []registeredListener {
+	listenersMutex.RLock()
+	defer listenersMutex.RUnlock()
+	return listeners
+}
+
+func runListeners(chain []registeredListener, entry Entry) {
+	for _, l := range chain {
+		l.listener(entry)
+	}
+}
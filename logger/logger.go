@@ -1,31 +1,58 @@
 package logger
 
 import (
-	"fmt"
-	"runtime"
+	"context"
+	"io"
+	"os"
 	"time"
 
 	"github.com/labstack/gommon/log"
 	goLoggerAppName "github.com/pobyzaarif/go-logger/appname"
 )
 
+// packagePath is the import path of this package, used to skip its own stack frames
+const packagePath = "github.com/pobyzaarif/go-logger/logger"
+
 var (
-	logger = new()
-	app    = goLoggerAppName.GetAPPName()
+	output = &syncWriter{w: os.Stdout}
+	// time is part of the log params so buffered entries keep the time they were logged at
+	logger     = new(`{"level":"${level}"}`)
+	textLogger = new(`${level}`)
+	app        = goLoggerAppName.GetAPPName()
+	service    = goLoggerAppName.GetServiceInfo().Fields()
 )
 
-func new() *log.Logger {
+// new returns a gommon logger writing to output, one logger per format so the header never changes while logging.
+func new(header string) *log.Logger {
 	l := log.New("")
+	l.SetOutput(output)
 	l.DisableColor()
-	l.SetHeader(`{"time":"${time_rfc3339_nano}","level":"${level}"}`)
+	l.SetHeader(header)
 	return l
 }
 
+// SetOutput sets the writer receiving log entries, one JSON line per entry. It is safe to call while logging.
+func SetOutput(w io.Writer) {
+	output.set(w)
+}
+
+// Output returns the writer receiving log entries.
+func Output() io.Writer {
+	return output.get()
+}
+
 type newLog struct {
 	tag        string
 	trackerID  string
 	Caller     string // for manipulate or customizing caller value
+	callerSkip int
 	timerStart time.Time
+	timerEnd   time.Time
+	timer      *Timer
+
+	spanID        string
+	parentSpanID  string
+	contextFields map[string]interface{}
 }
 
 func NewLog(tag string) newLog {
@@ -34,57 +61,191 @@ func NewLog(tag string) newLog {
 	}
 }
 
-func (newLog *newLog) newLogParams(message string, data map[string]interface{}, err error) map[string]interface{} {
+func (newLog *newLog) newLogParams(level Level, message string, data map[string]interface{}, err error) (Level, map[string]interface{}) {
+	settings := loadSettings()
+	enabled := settings.enabled(newLog.tag, level)
+	chain := currentListeners()
+	if !enabled && len(chain) == 0 {
+		newLog.timerStart, newLog.timerEnd = time.Time{}, time.Time{}
+		return level, nil
+	}
+
+	entry, timeStart, timeEnd := newLog.newEntry(settings, level, message, data, err)
+	runListeners(chain, *entry)
+	if !enabled {
+		return level, nil
+	}
+
 	logParams := make(map[string]interface{})
 
-	if newLog.Caller == "" {
-		_, file, line, ok := runtime.Caller(2)
-		if ok {
-			logParams["caller"] = fmt.Sprintf("%v:%v", file, line)
-		} else {
-			logParams["caller"] = ""
+	callerConfig := currentCallerConfig()
+	if newLog.Caller != "" {
+		logParams["caller"] = newLog.Caller
+	} else if callerConfig.Disable {
+		logParams["caller"] = ""
+	} else {
+		file, function := caller(2+newLog.callerSkip, callerConfig)
+		logParams["caller"] = file
+		if callerConfig.Function {
+			logParams["caller_function"] = function
+		}
+	}
+
+	logParams["time"] = settings.formatTime(time.Now())
+	logParams["service_name"] = settings.app
+	logParams["service"] = settings.service
+
+	if newLog.timer != nil {
+		logParams["timer"] = newLog.timer.fields()
+	}
+
+	logParams["timer_start"] = settings.formatTime(timeStart)
+	logParams["timer_end"] = settings.formatTime(timeEnd)
+	logParams["processing_time"] = settings.formatDuration(timeEnd.Sub(timeStart))
+	if settings.durationUnit != time.Millisecond {
+		logParams["processing_time_unit"] = durationUnitName(settings.durationUnit)
+	}
+
+	// span IDs of a context extractor, e.g. OpenTelemetry, replace the go-logger span
+	if _, ok := newLog.contextFields["span_id"]; newLog.spanID != "" && !ok {
+		logParams["span_id"] = newLog.spanID
+		if newLog.parentSpanID != "" {
+			logParams["parent_span_id"] = newLog.parentSpanID
+		}
+	}
+
+	// only error entries are grouped, the stack walk is too costly for every warning
+	if err != nil && level >= ErrorLevel {
+		entry.Fingerprint = Fingerprint(entry.Tag, entry.Message, err, errorFrames(err, fingerprintFrames))
+	}
+	if !runHooks(entry) && entry.Level != FatalLevel {
+		return entry.Level, nil
+	}
+
+	for k, v := range entry.Fields {
+		logParams[k] = v
+	}
+	logParams["message"] = entry.Message
+	logParams["tag"] = entry.Tag
+	logParams["tracker_id"] = entry.TrackerID
+	logParams["data"] = settings.formatTimes(entry.Data)
+	if entry.Data == nil {
+		logParams["data"] = make(map[string]interface{})
+	}
+
+	if entry.Fingerprint != "" {
+		logParams["error_fingerprint"] = entry.Fingerprint
+	}
+	if entry.Error != nil {
+		logParams["error"] = entry.Error.Error()
+		if detail := errorDetail(entry.Level, entry.Error); detail != nil {
+			logParams["error_detail"] = detail
+		}
+		if fields := errorFields(entry.Error); fields != nil {
+			logParams["error_fields"] = redact(fields, settings.redactKeys)
 		}
 	} else {
-		logParams["caller"] = newLog.Caller
+		logParams["error"] = ""
 	}
 
-	logParams["service_name"] = app
-	logParams["message"] = message
-	logParams["tag"] = newLog.tag
-	logParams["tracker_id"] = newLog.trackerID
+	return entry.Level, logParams
+}
 
-	timeStart := time.Now()
+// newEntry builds the entry passed to the listeners and the hooks, and resets the timer of newLog.
+func (newLog *newLog) newEntry(settings settings, level Level, message string, data map[string]interface{}, err error) (entry *Entry, timeStart, timeEnd time.Time) {
+	timeEnd = time.Now()
+	if !newLog.timerEnd.IsZero() {
+		timeEnd = newLog.timerEnd
+	}
+	timeStart = timeEnd
 	if !newLog.timerStart.IsZero() {
 		timeStart = newLog.timerStart
 	}
+	newLog.timerStart, newLog.timerEnd = time.Time{}, time.Time{}
 
-	elapsed := time.Since(timeStart)
-	logParams["timer_start"] = timeStart
-	logParams["timer_end"] = time.Now()
-	logParams["processing_time"] = float64(elapsed.Nanoseconds() / 1e6)
-	newLog.timerStart = time.Time{}
-
+	var dataParams map[string]interface{}
 	if data != nil {
 		// detect which one is gologger default
 		if def, _ := data["__gologger__"].(int); def > 0 {
 			delete(data, "__gologger__")
-			logParams["data"] = data
+			dataParams = data
 		} else {
-			logParams["data"] = map[string]interface{}{
+			dataParams = map[string]interface{}{
 				"app": data,
 			}
 		}
 	} else {
-		logParams["data"] = make(map[string]interface{})
+		dataParams = make(map[string]interface{})
+	}
+
+	// data is redacted before the listeners and hooks, they may send it to external services (e.g. alert webhooks)
+	entry = &Entry{
+		Level:     level,
+		Tag:       newLog.tag,
+		Message:   message,
+		TrackerID: newLog.trackerID,
+		Data:      redact(dataParams, settings.redactKeys),
+		Error:     err,
+		Fields:    make(map[string]interface{}, len(newLog.contextFields)),
+		Duration:  timeEnd.Sub(timeStart),
+	}
+	for k, v := range newLog.contextFields {
+		entry.Fields[k] = v
+	}
+	return entry, timeStart, timeEnd
+}
+
+// Notify passes an entry to the listeners without logging it, for operations measured but not logged
+// at the current verbosity, e.g. the gorm queries below its LogLevel. The level is Error when err is set.
+func (newLog *newLog) Notify(message string, data map[string]interface{}, err error) {
+	chain := currentListeners()
+	if len(chain) == 0 {
+		newLog.timerStart, newLog.timerEnd = time.Time{}, time.Time{}
+		return
 	}
 
+	level := InfoLevel
 	if err != nil {
-		logParams["error"] = err.Error()
-	} else {
-		logParams["error"] = ""
+		level = ErrorLevel
+	}
+	entry, _, _ := newLog.newEntry(loadSettings(), level, message, data, err)
+	runListeners(chain, *entry)
+}
+
+// write sends logParams through the sampling stages to the gommon logger, nil logParams were dropped by a hook.
+func write(level Level, logParams map[string]interface{}) {
+	if logParams == nil {
+		return
+	}
+	if !sample(level, logParams) {
+		return
+	}
+	if deduplicate(level, logParams) {
+		return
+	}
+	if bufferTail(level, logParams) {
+		return
+	}
+
+	emit(level, logParams)
+}
+
+func emit(level Level, logParams map[string]interface{}) {
+	if loadSettings().format == "text" {
+		emitText(level, logParams)
+		return
 	}
 
-	return logParams
+	switch level {
+	case InfoLevel:
+		logger.Infoj(logParams)
+	case WarnLevel:
+		logger.Warnj(logParams)
+	case ErrorLevel:
+		logger.Errorj(logParams)
+	case FatalLevel:
+		logger.Fatalj(logParams)
+	}
 }
 
 func (newLog *newLog) TimerStart() {
@@ -103,52 +264,74 @@ func (newLog *newLog) SetCallerValue(caller string) {
 	newLog.Caller = caller
 }
 
+// SetSpan stamps span_id and parent_span_id of span on the next log lines, and its tracker ID when none is set.
+func (newLog *newLog) SetSpan(span *Span) {
+	if span == nil {
+		newLog.spanID, newLog.parentSpanID = "", ""
+		return
+	}
+	newLog.spanID, newLog.parentSpanID = span.id, span.parentID
+	if newLog.trackerID == "" {
+		newLog.trackerID = span.trackerID
+	}
+}
+
+// SetContext sets the tracker ID from the "tracker_id" value of ctx, the span from the span of ctx
+// and the fields of the registered context extractors, see AddContextExtractor.
+func (newLog *newLog) SetContext(ctx context.Context) {
+	if trackerID, ok := ctx.Value("tracker_id").(string); ok && trackerID != "" {
+		newLog.trackerID = trackerID
+	}
+	newLog.SetSpan(SpanFromContext(ctx))
+	newLog.contextFields = contextFields(ctx)
+}
+
 func (newLog *newLog) Info(message string) {
-	logParams := newLog.newLogParams(message, nil, nil)
-	logger.Infoj(logParams)
+	level, logParams := newLog.newLogParams(InfoLevel, message, nil, nil)
+	write(level, logParams)
 }
 
 func (newLog *newLog) InfoWithData(message string, data map[string]interface{}) {
-	logParams := newLog.newLogParams(message, data, nil)
-	logger.Infoj(logParams)
+	level, logParams := newLog.newLogParams(InfoLevel, message, data, nil)
+	write(level, logParams)
 }
 
 func (newLog *newLog) Warn(message string) {
-	logParams := newLog.newLogParams(message, nil, nil)
-	logger.Warnj(logParams)
+	level, logParams := newLog.newLogParams(WarnLevel, message, nil, nil)
+	write(level, logParams)
 }
 
 func (newLog *newLog) WarnWithData(message string, data map[string]interface{}) {
-	logParams := newLog.newLogParams(message, data, nil)
-	logger.Warnj(logParams)
+	level, logParams := newLog.newLogParams(WarnLevel, message, data, nil)
+	write(level, logParams)
 }
 
 func (newLog *newLog) WarnWithDataAndError(message string, data map[string]interface{}, err error) {
-	logParams := newLog.newLogParams(message, data, err)
-	logger.Warnj(logParams)
+	level, logParams := newLog.newLogParams(WarnLevel, message, data, err)
+	write(level, logParams)
 }
 
 func (newLog *newLog) Error(message string, err error) {
-	logParams := newLog.newLogParams(message, nil, err)
-	logger.Errorj(logParams)
+	level, logParams := newLog.newLogParams(ErrorLevel, message, nil, err)
+	write(level, logParams)
 }
 
 func (newLog *newLog) ErrorWithData(message string, data map[string]interface{}, err error) {
-	logParams := newLog.newLogParams(message, data, err)
-	logger.Errorj(logParams)
+	level, logParams := newLog.newLogParams(ErrorLevel, message, data, err)
+	write(level, logParams)
 }
 
 func (newLog *newLog) Fatal(message string) {
-	logParams := newLog.newLogParams(message, nil, nil)
-	logger.Fatalj(logParams)
+	level, logParams := newLog.newLogParams(FatalLevel, message, nil, nil)
+	write(level, logParams)
 }
 
 func (newLog *newLog) FatalWithData(message string, data map[string]interface{}) {
-	logParams := newLog.newLogParams(message, data, nil)
-	logger.Fatalj(logParams)
+	level, logParams := newLog.newLogParams(FatalLevel, message, data, nil)
+	write(level, logParams)
 }
 
 func (newLog *newLog) FatalWithDataAndError(message string, data map[string]interface{}, err error) {
-	logParams := newLog.newLogParams(message, data, err)
-	logger.Fatalj(logParams)
+	level, logParams := newLog.newLogParams(FatalLevel, message, data, err)
+	write(level, logParams)
 }
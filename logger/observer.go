@@ -0,0 +1,352 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	goLoggerHttp "github.com/pobyzaarif/go-logger/http"
+)
+
+type (
+	// ObservedEntry is a log entry decoded by an Observer.
+	ObservedEntry struct {
+		Time      time.Time
+		Level     string
+		Tag       string
+		Message   string
+		TrackerID string
+		Error     string
+		// Fields is the whole decoded JSON line.
+		Fields map[string]interface{}
+	}
+
+	// Observer is an io.Writer keeping decoded log entries in memory, see ReplaceGlobalsForTest.
+	Observer struct {
+		entries []ObservedEntry
+		mutex   sync.Mutex
+	}
+
+	// TestingT is the subset of testing.TB used by the test helpers.
+	TestingT interface {
+		Helper()
+		Cleanup(func())
+		Errorf(format string, args ...interface{})
+	}
+)
+
+// UpdateGoldenEnv is the environment variable making AssertGolden rewrite golden files instead of comparing them.
+const UpdateGoldenEnv = "GOLOGGER_UPDATE_GOLDEN"
+
+func NewObserver() *Observer {
+	return &Observer{}
+}
+
+// ReplaceGlobalsForTest sends log entries to a new Observer and restores the global logger state when the test ends.
+func ReplaceGlobalsForTest(t TestingT) *Observer {
+	t.Helper()
+
+	restore := saveGlobals()
+	observer := NewObserver()
+	SetOutput(observer)
+	t.Cleanup(restore)
+
+	return observer
+}
+
+// saveGlobals returns a function restoring the current global logger state.
+func saveGlobals() func() {
+	output := Output()
+	previousSettings := loadSettings()
+	previousHiddenHeaders := goLoggerHttp.HiddenHeaders()
+	configMutex.Lock()
+	previousAppliedConfig := appliedConfig
+	configMutex.Unlock()
+
+	hooksMutex.RLock()
+	previousHooks := hooks
+	hooksMutex.RUnlock()
+	listenersMutex.RLock()
+	previousListeners := listeners
+	listenersMutex.RUnlock()
+	contextExtractorsMutex.RLock()
+	previousContextExtractors := contextExtractors
+	contextExtractorsMutex.RUnlock()
+	previousErrorConfig := currentErrorConfig()
+	previousCallerConfig := currentCallerConfig()
+	previousSpanLogging := spanLogging.Load()
+
+	samplerMutex.RLock()
+	previousSampler := sampler
+	samplerMutex.RUnlock()
+	deduplicatorMutex.RLock()
+	previousDeduplicator := deduplicator
+	deduplicatorMutex.RUnlock()
+	tailSamplerMutex.RLock()
+	previousTailSampler := tailSampler
+	tailSamplerMutex.RUnlock()
+
+	return func() {
+		SetOutput(output)
+		currentSettings.Store(previousSettings)
+		goLoggerHttp.SetHiddenHeaders(previousHiddenHeaders)
+		configMutex.Lock()
+		appliedConfig = previousAppliedConfig
+		configMutex.Unlock()
+
+		hooksMutex.Lock()
+		hooks = previousHooks
+		hooksMutex.Unlock()
+		listenersMutex.Lock()
+		listeners = previousListeners
+		listenersMutex.Unlock()
+		contextExtractorsMutex.Lock()
+		contextExtractors = previousContextExtractors
+		contextExtractorsMutex.Unlock()
+		SetErrorConfig(previousErrorConfig)
+		SetCallerConfig(previousCallerConfig)
+		SetSpanLogging(previousSpanLogging)
+
+		samplerMutex.RLock()
+		changed := sampler != previousSampler
+		samplerMutex.RUnlock()
+		if changed && previousSampler == nil {
+			DisableSampling()
+		} else if changed {
+			SetSampling(previousSampler.config)
+		}
+
+		deduplicatorMutex.RLock()
+		changed = deduplicator != previousDeduplicator
+		deduplicatorMutex.RUnlock()
+		if changed && previousDeduplicator == nil {
+			DisableDedup()
+		} else if changed {
+			SetDedup(previousDeduplicator.config)
+		}
+
+		tailSamplerMutex.RLock()
+		changed = tailSampler != previousTailSampler
+		tailSamplerMutex.RUnlock()
+		if changed && previousTailSampler == nil {
+			DisableTailSampling()
+		} else if changed {
+			SetTailSampling(previousTailSampler.config)
+		}
+	}
+}
+
+func (o *Observer) Write(p []byte) (int, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(p))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(p)+1)
+	for scanner.Scan() {
+		fields := make(map[string]interface{})
+		if err := json.Unmarshal(scanner.Bytes(), &fields); err != nil {
+			continue
+		}
+
+		entry := ObservedEntry{Fields: fields}
+		entry.Time, _ = ParseTime(fields["time"])
+		entry.Level = stringField(fields, "level")
+		entry.Tag = stringField(fields, "tag")
+		entry.Message = stringField(fields, "message")
+		entry.TrackerID = stringField(fields, "tracker_id")
+		entry.Error = stringField(fields, "error")
+
+		o.mutex.Lock()
+		o.entries = append(o.entries, entry)
+		o.mutex.Unlock()
+	}
+
+	return len(p), nil
+}
+
+// All returns the observed entries.
+func (o *Observer) All() []ObservedEntry {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	return append([]ObservedEntry{}, o.entries...)
+}
+
+// Len returns the number of observed entries.
+func (o *Observer) Len() int {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	return len(o.entries)
+}
+
+// TakeAll returns the observed entries and drops them.
+func (o *Observer) TakeAll() []ObservedEntry {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	entries := o.entries
+	o.entries = nil
+	return entries
+}
+
+// Filter returns an Observer holding the entries for which keep returns true.
+func (o *Observer) Filter(keep func(ObservedEntry) bool) *Observer {
+	filtered := NewObserver()
+	for _, entry := range o.All() {
+		if keep(entry) {
+			filtered.entries = append(filtered.entries, entry)
+		}
+	}
+	return filtered
+}
+
+// FilterLevel keeps the entries logged at level, case insensitive (e.g. "error").
+func (o *Observer) FilterLevel(level string) *Observer {
+	return o.Filter(func(e ObservedEntry) bool { return strings.EqualFold(e.Level, level) })
+}
+
+// FilterTag keeps the entries logged with tag.
+func (o *Observer) FilterTag(tag string) *Observer {
+	return o.Filter(func(e ObservedEntry) bool { return e.Tag == tag })
+}
+
+// FilterMessage keeps the entries logged with message.
+func (o *Observer) FilterMessage(message string) *Observer {
+	return o.Filter(func(e ObservedEntry) bool { return e.Message == message })
+}
+
+// FilterMessageSnippet keeps the entries whose message contains snippet.
+func (o *Observer) FilterMessageSnippet(snippet string) *Observer {
+	return o.Filter(func(e ObservedEntry) bool { return strings.Contains(e.Message, snippet) })
+}
+
+// Golden returns the observed entries normalized by NormalizeEntry, one JSON line per entry.
+func (o *Observer) Golden() []byte {
+	var buffer bytes.Buffer
+	for _, entry := range o.All() {
+		b, _ := json.Marshal(NormalizeEntry(entry.Fields))
+		buffer.Write(b)
+		buffer.WriteByte('\n')
+	}
+	return buffer.Bytes()
+}
+
+// NormalizeEntry returns a copy of fields with the values changing between runs replaced by stable placeholders:
+// time, timer_start, timer_end, first_seen, last_seen, the RFC3339 times of data, processing_time, service metadata,
+// span IDs, the caller line and the file paths and lines of error_detail.stack.
+func NormalizeEntry(fields map[string]interface{}) map[string]interface{} {
+	normalized := make(map[string]interface{}, len(fields))
+	for k, v := range fields {
+		normalized[k] = v
+	}
+
+	for _, k := range []string{"time", "timer_start", "timer_end", "first_seen", "last_seen"} {
+		if _, ok := normalized[k]; ok {
+			normalized[k] = "TIME"
+		}
+	}
+	if _, ok := normalized["service"]; ok {
+		normalized["service"] = "SERVICE"
+	}
+	if _, ok := normalized["processing_time"]; ok {
+		normalized["processing_time"] = 0
+	}
+	for _, k := range []string{"span_id", "parent_span_id"} {
+		if _, ok := normalized[k]; ok {
+			normalized[k] = "SPAN_ID"
+		}
+	}
+	if caller, ok := normalized["caller"].(string); ok && caller != "" {
+		if i := strings.LastIndex(caller, ":"); i >= 0 {
+			caller = caller[:i]
+		}
+		normalized["caller"] = path.Base(caller)
+	}
+	if data, ok := normalized["data"]; ok {
+		normalized["data"] = normalizeTimes(data)
+	}
+	if detail, ok := normalized["error_detail"].(map[string]interface{}); ok {
+		normalized["error_detail"] = normalizeErrorDetail(detail)
+	}
+
+	return normalized
+}
+
+// normalizeTimes returns a copy of value where the strings holding an RFC3339 time at any depth are replaced by "TIME".
+func normalizeTimes(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		if _, err := time.Parse(time.RFC3339Nano, v); err == nil {
+			return "TIME"
+		}
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			copied[key] = normalizeTimes(val)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(v))
+		for i, val := range v {
+			copied[i] = normalizeTimes(val)
+		}
+		return copied
+	}
+	return value
+}
+
+// normalizeErrorDetail returns a copy of detail with the stack frames "function /path/file.go:line" as "function file.go".
+func normalizeErrorDetail(detail map[string]interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(detail))
+	for k, v := range detail {
+		copied[k] = v
+	}
+
+	stack, ok := detail["stack"].([]interface{})
+	if !ok {
+		return copied
+	}
+	frames := make([]interface{}, len(stack))
+	for i, frame := range stack {
+		s, _ := frame.(string)
+		if line := strings.LastIndex(s, ":"); line >= 0 {
+			s = s[:line]
+		}
+		if file := strings.Index(s, " "); file >= 0 {
+			s = s[:file+1] + path.Base(s[file+1:])
+		}
+		frames[i] = s
+	}
+	copied["stack"] = frames
+	return copied
+}
+
+// AssertGolden compares got with the golden file at path, or rewrites the file when UpdateGoldenEnv is set.
+func AssertGolden(t TestingT, path string, got []byte) {
+	t.Helper()
+
+	if os.Getenv(UpdateGoldenEnv) != "" {
+		if err := ioutil.WriteFile(path, got, 0644); err != nil {
+			t.Errorf("failed to update golden file %s: %v", path, err)
+		}
+		return
+	}
+
+	want, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Errorf("failed to read golden file %s: %v (run with %s=1 to create it)", path, err, UpdateGoldenEnv)
+		return
+	}
+	if !bytes.Equal(want, got) {
+		t.Errorf("log output does not match golden file %s (run with %s=1 to update it)\nwant:\n%s\ngot:\n%s", path, UpdateGoldenEnv, want, got)
+	}
+}
+
+func stringField(fields map[string]interface{}, key string) string {
+	s, _ := fields[key].(string)
+	return s
+}
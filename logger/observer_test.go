@@ -0,0 +1,120 @@
+package logger
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// stackError carries the stack it was created at, as errors of github.com/go-errors/errors.
+type stackError struct {
+	message string
+	pcs     []uintptr
+}
+
+func newStackError(message string) *stackError {
+	pcs := make([]uintptr, 32)
+	return &stackError{message: message, pcs: pcs[:runtime.Callers(2, pcs)]}
+}
+
+func (e *stackError) Error() string         { return e.message }
+func (e *stackError) StackTrace() []uintptr { return e.pcs }
+
+// logGoldenEntries logs an entry with times in data, an error with a stack and a collapsed repeat.
+func logGoldenEntries(t *testing.T) []byte {
+	observer := ReplaceGlobalsForTest(t)
+	SetErrorConfig(ErrorConfig{StackDepth: 1})
+
+	log := NewLog("TEST")
+	log.SetTrackerID("abc")
+	log.InfoWithData("created", map[string]interface{}{
+		"created_at": time.Now(),
+		"history":    []interface{}{time.Now().Add(-time.Hour)},
+		"name":       "order",
+	})
+	log.ErrorWithData("failed", nil, fmt.Errorf("charge: %w", newStackError("card declined")))
+
+	SetDedup(DedupConfig{Window: time.Hour})
+	for i := 0; i < 3; i++ {
+		log.Warn("repeated")
+	}
+	DisableDedup()
+
+	return observer.Golden()
+}
+
+func TestGolden(t *testing.T) {
+	var runs [2][]byte
+	for i := range runs {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			runs[i] = logGoldenEntries(t)
+		})
+		time.Sleep(2 * time.Millisecond)
+	}
+	if string(runs[0]) != string(runs[1]) {
+		t.Fatalf("normalized entries differ between runs:\n%s\n%s", runs[0], runs[1])
+	}
+
+	AssertGolden(t, filepath.Join("testdata", "observer.golden"), runs[0])
+}
+
+type recordingT struct {
+	testing.TB
+	errors []string
+}
+
+func (t *recordingT) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func TestAssertGoldenUpdate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.golden")
+
+	t.Setenv(UpdateGoldenEnv, "1")
+	AssertGolden(t, path, []byte("line\n"))
+
+	t.Setenv(UpdateGoldenEnv, "")
+	AssertGolden(t, path, []byte("line\n"))
+
+	recorder := &recordingT{TB: t}
+	AssertGolden(recorder, path, []byte("other line\n"))
+	AssertGolden(recorder, filepath.Join(t.TempDir(), "missing.golden"), nil)
+	if len(recorder.errors) != 2 {
+		t.Errorf("got errors %q, want a mismatch and a missing file", recorder.errors)
+	}
+}
+
+func TestNormalizeEntry(t *testing.T) {
+	fields := map[string]interface{}{
+		"time":         "2026-10-19T10:00:00.123+07:00",
+		"first_seen":   "2026-10-19T10:00:00Z",
+		"last_seen":    "2026-10-19T10:00:01Z",
+		"caller":       "/home/me/app/order/service.go:12",
+		"data":         map[string]interface{}{"at": "2026-10-19T10:00:00Z", "name": "order", "count": 1.0},
+		"error_detail": map[string]interface{}{"stack": []interface{}{"main.run /home/me/my app/main.go:22"}},
+	}
+
+	normalized := NormalizeEntry(fields)
+	want := map[string]interface{}{
+		"time":       "TIME",
+		"first_seen": "TIME",
+		"last_seen":  "TIME",
+		"caller":     "service.go",
+	}
+	for k, v := range want {
+		if normalized[k] != v {
+			t.Errorf("got %s %v, want %v", k, normalized[k], v)
+		}
+	}
+	if data := normalized["data"].(map[string]interface{}); data["at"] != "TIME" || data["name"] != "order" || data["count"] != 1.0 {
+		t.Errorf("got data %v", data)
+	}
+	if stack := normalized["error_detail"].(map[string]interface{})["stack"].([]interface{}); stack[0] != "main.run main.go" {
+		t.Errorf("got stack %v, want [main.run main.go]", stack)
+	}
+	if fields["data"].(map[string]interface{})["at"] == "TIME" || fields["time"] == "TIME" {
+		t.Error("NormalizeEntry modified its argument")
+	}
+}
@@ -0,0 +1,33 @@
+package logger
+
+import (
+	"io"
+	"sync"
+)
+
+// syncWriter is the writer of the gommon loggers, its destination can be replaced while entries are written.
+type syncWriter struct {
+	w     io.Writer
+	mutex sync.Mutex
+}
+
+func (s *syncWriter) Write(p []byte) (int, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.w.Write(p)
+}
+
+func (s *syncWriter) set(w io.Writer) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.w = w
+}
+
+func (s *syncWriter) get() io.Writer {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.w
+}
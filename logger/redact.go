@@ -0,0 +1,53 @@
+package logger
+
+import "strings"
+
+// redacted replaces the values of redacted keys
+const redacted = "**hidden**"
+
+// redact returns a copy of data whose values of keys, at any depth, are replaced by "**hidden**".
+// data is returned as is when there is nothing to redact.
+func redact(data map[string]interface{}, keys map[string]bool) map[string]interface{} {
+	if len(keys) == 0 || data == nil {
+		return data
+	}
+	return redactValue(data, keys).(map[string]interface{})
+}
+
+func redactValue(value interface{}, keys map[string]bool) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			if keys[strings.ToLower(key)] {
+				copied[key] = redacted
+			} else {
+				copied[key] = redactValue(val, keys)
+			}
+		}
+		return copied
+	case map[string]string:
+		copied := make(map[string]string, len(v))
+		for key, val := range v {
+			if keys[strings.ToLower(key)] {
+				copied[key] = redacted
+			} else {
+				copied[key] = val
+			}
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(v))
+		for i, val := range v {
+			copied[i] = redactValue(val, keys)
+		}
+		return copied
+	case []map[string]interface{}:
+		copied := make([]map[string]interface{}, len(v))
+		for i, val := range v {
+			copied[i] = redactValue(val, keys).(map[string]interface{})
+		}
+		return copied
+	}
+	return value
+}
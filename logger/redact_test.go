@@ -0,0 +1,52 @@
+package logger
+
+import (
+	"errors"
+	"testing"
+)
+
+type detailedError struct {
+	details map[string]interface{}
+}
+
+func (e detailedError) Error() string {
+	return "detailed"
+}
+
+func (e detailedError) LogFields() map[string]interface{} {
+	return map[string]interface{}{"details": e.details}
+}
+
+func TestRedactionRunsBeforeHooks(t *testing.T) {
+	observer := ReplaceGlobalsForTest(t)
+	config := DefaultConfig
+	config.Redaction.Keys = []string{"password"}
+	if err := Configure(config); err != nil {
+		t.Fatal(err)
+	}
+	SetOutput(observer)
+
+	var hooked map[string]interface{}
+	remove := AddHook(func(entry *Entry) bool {
+		hooked = entry.Data
+		return true
+	})
+	defer remove()
+
+	err := detailedError{details: map[string]interface{}{"password": "secret", "user": "alice"}}
+	log := NewLog("TEST")
+	log.ErrorWithData("failed", map[string]interface{}{"password": "secret"}, errors.Join(err))
+
+	if got := hooked["app"].(map[string]interface{})["password"]; got != redacted {
+		t.Errorf("hook got password %v, want %v", got, redacted)
+	}
+
+	entries := observer.All()
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(entries))
+	}
+	details := entries[0].Fields["error_fields"].(map[string]interface{})["details"].(map[string]interface{})
+	if details["password"] != redacted || details["user"] != "alice" {
+		t.Errorf("got error_fields.details %v, want the password redacted", details)
+	}
+}
@@ -0,0 +1,152 @@
+package logger
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+)
+
+// WatchConfig configures the logger from the config file at path, overridden by the GOLOGGER_* environment
+// variables (see ConfigFromEnv), then polls the file every interval and applies its changes live.
+// A changed config is logged with the diff of its values, an invalid one is logged and rejected while
+// the running config is kept. stop ends the watch.
+func WatchConfig(path string, interval time.Duration) (stop func(), err error) {
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+
+	config, err := loadWatchedConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := Configure(config); err != nil {
+		return nil, err
+	}
+
+	b, _ := ioutil.ReadFile(path)
+	checksum := sha256.Sum256(b)
+
+	done := make(chan struct{})
+	var once sync.Once
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				b, err := ioutil.ReadFile(path)
+				if err != nil {
+					reloadLog := NewLog("GOLOGGER")
+					reloadLog.WarnWithDataAndError("config_reload_failed", reloadData(path, nil), err)
+					continue
+				}
+				if sum := sha256.Sum256(b); sum != checksum {
+					checksum = sum
+					config = reload(path, config)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() { once.Do(func() { close(done) }) }, nil
+}
+
+// reload applies the config file at path and returns the running config.
+func reload(path string, running Config) Config {
+	reloadLog := NewLog("GOLOGGER")
+	config, err := loadWatchedConfig(path)
+	if err != nil {
+		reloadLog.ErrorWithData("config_reload_rejected", reloadData(path, nil), err)
+		return running
+	}
+
+	// logged before applying so a stricter level does not hide it
+	reloadLog.InfoWithData("config_reloaded", reloadData(path, ConfigDiff(running, config)))
+	if err := Configure(config); err != nil {
+		reloadLog.ErrorWithData("config_reload_rejected", reloadData(path, nil), err)
+		return running
+	}
+	return config
+}
+
+func loadWatchedConfig(path string) (Config, error) {
+	// a file being written may be read empty
+	if b, err := ioutil.ReadFile(path); err == nil && len(bytes.TrimSpace(b)) == 0 {
+		return Config{}, fmt.Errorf("empty config file %s", path)
+	}
+
+	config, err := LoadConfig(path)
+	if err != nil {
+		return config, err
+	}
+	return ConfigFromEnv(config)
+}
+
+func reloadData(path string, diff map[string]interface{}) map[string]interface{} {
+	config := map[string]interface{}{"path": path}
+	if diff != nil {
+		config["diff"] = diff
+	}
+	return map[string]interface{}{
+		"__gologger__": 1,
+		"config":       config,
+	}
+}
+
+// ConfigDiff returns the values changed from old to new, keyed by their dotted path,
+// e.g. {"level": {"old": "info", "new": "warn"}}.
+func ConfigDiff(old, new Config) map[string]interface{} {
+	oldValues, newValues := flattenConfig(old), flattenConfig(new)
+
+	keys := make([]string, 0, len(oldValues)+len(newValues))
+	for key := range oldValues {
+		keys = append(keys, key)
+	}
+	for key := range newValues {
+		if _, ok := oldValues[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	diff := make(map[string]interface{})
+	for _, key := range keys {
+		if !reflect.DeepEqual(oldValues[key], newValues[key]) {
+			diff[key] = map[string]interface{}{"old": oldValues[key], "new": newValues[key]}
+		}
+	}
+	return diff
+}
+
+func flattenConfig(config Config) map[string]interface{} {
+	b, _ := json.Marshal(config)
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+	var m map[string]interface{}
+	_ = decoder.Decode(&m)
+
+	flat := make(map[string]interface{})
+	var walk func(prefix string, value interface{})
+	walk = func(prefix string, value interface{}) {
+		if nested, ok := value.(map[string]interface{}); ok && len(nested) > 0 {
+			for key, v := range nested {
+				walk(fmt.Sprintf("%s%s.", prefix, key), v)
+			}
+			return
+		}
+		flat[prefix[:len(prefix)-1]] = value
+	}
+	for key, value := range m {
+		walk(key+".", value)
+	}
+	return flat
+}
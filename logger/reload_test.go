@@ -0,0 +1,149 @@
+package logger
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// readLines returns the decoded JSON lines of the log file at path.
+func readLines(t *testing.T, path string) []map[string]interface{} {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var lines []map[string]interface{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var line map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("invalid log line %q: %v", scanner.Text(), err)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// waitLine waits for the first line of the log file at path with message.
+func waitLine(t *testing.T, path, message string) map[string]interface{} {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		for _, line := range readLines(t, path) {
+			if line["message"] == message {
+				return line
+			}
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("no %s line logged", message)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func countMessage(lines []map[string]interface{}, message string) int {
+	n := 0
+	for _, line := range lines {
+		if line["message"] == message {
+			n++
+		}
+	}
+	return n
+}
+
+func TestWatchConfig(t *testing.T) {
+	ReplaceGlobalsForTest(t)
+	dir := t.TempDir()
+	output := filepath.Join(dir, "out.log")
+	path := filepath.Join(dir, "gologger.yaml")
+
+	writeConfig := func(content string) {
+		t.Helper()
+		if err := ioutil.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeConfig("level: info\noutputs: [" + output + "]\n")
+
+	stop, err := WatchConfig(path, 10*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stop()
+
+	log := NewLog("TEST")
+	log.Info("before reload")
+
+	writeConfig("level: warn\noutputs: [" + output + "]\n")
+	reloaded := waitLine(t, output, "config_reloaded")
+	diff := reloaded["data"].(map[string]interface{})["config"].(map[string]interface{})["diff"].(map[string]interface{})
+	if level, _ := diff["level"].(map[string]interface{}); level["old"] != "info" || level["new"] != "warn" || len(diff) != 1 {
+		t.Errorf("got diff %v, want only level from info to warn", diff)
+	}
+
+	log.Info("after reload")
+	log.Warn("warning after reload")
+
+	// the running config stays in place when the file is invalid
+	writeConfig("level: verbose\noutputs: [" + output + "]\n")
+	rejected := waitLine(t, output, "config_reload_rejected")
+	if rejected["level"] != "ERROR" || rejected["error"] == "" {
+		t.Errorf("got rejected line %v, want an error entry with the validation error", rejected)
+	}
+	log.Info("after rejected reload")
+	log.Warn("warning after rejected reload")
+
+	lines := readLines(t, output)
+	for message, want := range map[string]int{
+		"before reload":                 1,
+		"after reload":                  0,
+		"warning after reload":          1,
+		"after rejected reload":         0,
+		"warning after rejected reload": 1,
+	} {
+		if n := countMessage(lines, message); n != want {
+			t.Errorf("got %d %q lines, want %d", n, message, want)
+		}
+	}
+}
+
+func TestWatchConfigRejectsInvalidFile(t *testing.T) {
+	ReplaceGlobalsForTest(t)
+	path := filepath.Join(t.TempDir(), "gologger.json")
+	if err := ioutil.WriteFile(path, []byte(`{"level": "verbose"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if stop, err := WatchConfig(path, time.Hour); err == nil {
+		stop()
+		t.Fatal("got no error for an invalid config file")
+	}
+}
+
+func TestConfigDiff(t *testing.T) {
+	old := DefaultConfig
+	new := DefaultConfig
+	new.Level = "warn"
+	new.TagLevels = map[string]string{"MONGO_QUERY": "error"}
+	new.Sampling = &SamplingOptions{First: 10, Interval: Duration(time.Second)}
+
+	diff := ConfigDiff(old, new)
+	for _, key := range []string{"level", "tag_levels.MONGO_QUERY", "sampling.first", "sampling.interval"} {
+		if _, ok := diff[key]; !ok {
+			t.Errorf("no %s in diff %v", key, diff)
+		}
+	}
+	if got := diff["sampling.interval"].(map[string]interface{})["new"]; got != "1s" {
+		t.Errorf("got sampling.interval %v, want 1s", got)
+	}
+	if len(ConfigDiff(old, old)) != 0 {
+		t.Errorf("got diff %v of identical configs", ConfigDiff(old, old))
+	}
+}
@@ -0,0 +1,189 @@
+package logger
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+type (
+	// SamplingConfig defines how repetitive log entries are sampled.
+	SamplingConfig struct {
+		// Interval is the period the First and Thereafter counters are reset.
+		// Optional. Default value 1 second.
+		Interval time.Duration
+
+		// First is the number of entries logged per (tag, level, message) each interval.
+		// Optional. Default value 0 (no limit).
+		First int
+
+		// Thereafter logs 1 in Thereafter entries of a key beyond First, 0 drops them all.
+		// Optional. Default value 0.
+		Thereafter int
+
+		// TagRates keeps entries of a tag with the given probability, from 0 (drop all) to 1 (keep all).
+		// Optional. Default value nil.
+		TagRates map[string]float64
+
+		// KeepLevel is the level from which entries are always kept.
+		// Optional. Default value ErrorLevel.
+		KeepLevel Level
+
+		// SummaryInterval is how often a "sampling_summary" entry reports the suppressed entries.
+		// Optional. Default value 1 minute.
+		SummaryInterval time.Duration
+	}
+
+	sampling struct {
+		config      SamplingConfig
+		counts      map[string]int
+		suppressed  map[string]int
+		windowStart time.Time
+		stop        chan struct{}
+		mutex       sync.Mutex
+	}
+)
+
+var (
+	// DefaultSamplingConfig is the default sampling config.
+	DefaultSamplingConfig = SamplingConfig{
+		Interval:        time.Second,
+		First:           0,
+		Thereafter:      0,
+		KeepLevel:       ErrorLevel,
+		SummaryInterval: time.Minute,
+	}
+
+	sampler      *sampling
+	samplerMutex sync.RWMutex
+)
+
+// SetSampling enables sampling of log entries, replacing the previous sampling config.
+func SetSampling(config SamplingConfig) {
+	if config.Interval <= 0 {
+		config.Interval = DefaultSamplingConfig.Interval
+	}
+	if config.KeepLevel == 0 {
+		config.KeepLevel = DefaultSamplingConfig.KeepLevel
+	}
+	if config.SummaryInterval <= 0 {
+		config.SummaryInterval = DefaultSamplingConfig.SummaryInterval
+	}
+
+	s := &sampling{
+		config:      config,
+		counts:      make(map[string]int),
+		suppressed:  make(map[string]int),
+		windowStart: time.Now(),
+		stop:        make(chan struct{}),
+	}
+	go s.summarize()
+
+	replaceSampler(s)
+}
+
+// DisableSampling logs every entry again.
+func DisableSampling() {
+	replaceSampler(nil)
+}
+
+func replaceSampler(s *sampling) {
+	samplerMutex.Lock()
+	previous := sampler
+	sampler = s
+	samplerMutex.Unlock()
+
+	if previous != nil {
+		close(previous.stop)
+		previous.flush()
+	}
+}
+
+// sample reports whether the entry must be written.
+func sample(level Level, logParams map[string]interface{}) bool {
+	samplerMutex.RLock()
+	s := sampler
+	samplerMutex.RUnlock()
+	if s == nil || level >= s.config.KeepLevel || level == FatalLevel {
+		return true
+	}
+
+	tag, _ := logParams["tag"].(string)
+	key := fmt.Sprintf("%s|%s|%v", tag, level, logParams["message"])
+
+	if rate, ok := s.config.TagRates[tag]; ok && rand.Float64() >= rate {
+		s.suppress(key)
+		return false
+	}
+
+	if s.config.First <= 0 {
+		return true
+	}
+
+	s.mutex.Lock()
+	now := time.Now()
+	if now.Sub(s.windowStart) >= s.config.Interval {
+		s.counts = make(map[string]int)
+		s.windowStart = now
+	}
+	s.counts[key]++
+	n := s.counts[key]
+	keep := n <= s.config.First || (s.config.Thereafter > 0 && (n-s.config.First)%s.config.Thereafter == 0)
+	if !keep {
+		s.suppressed[key]++
+	}
+	s.mutex.Unlock()
+
+	return keep
+}
+
+func (s *sampling) suppress(key string) {
+	s.mutex.Lock()
+	s.suppressed[key]++
+	s.mutex.Unlock()
+}
+
+func (s *sampling) summarize() {
+	ticker := time.NewTicker(s.config.SummaryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.flush()
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// flush logs how many entries were suppressed per key since the last summary.
+func (s *sampling) flush() {
+	s.mutex.Lock()
+	suppressed := s.suppressed
+	s.suppressed = make(map[string]int)
+	s.mutex.Unlock()
+
+	if len(suppressed) == 0 {
+		return
+	}
+
+	total := 0
+	for _, n := range suppressed {
+		total += n
+	}
+
+	summaryLog := NewLog("GOLOGGER")
+	summaryLog.SetCallerValue("go-logger/sampling")
+	_, logParams := summaryLog.newLogParams(InfoLevel, "sampling_summary", map[string]interface{}{
+		"__gologger__": 1,
+		"sampling": map[string]interface{}{
+			"suppressed":       suppressed,
+			"total_suppressed": total,
+		},
+	}, nil)
+	if logParams != nil {
+		emit(InfoLevel, logParams)
+	}
+}
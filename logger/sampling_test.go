@@ -0,0 +1,56 @@
+package logger
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSamplingFirstThereafter(t *testing.T) {
+	observer := ReplaceGlobalsForTest(t)
+	SetSampling(SamplingConfig{Interval: time.Hour, First: 2, Thereafter: 3})
+
+	log := NewLog("TEST")
+	for i := 0; i < 10; i++ {
+		log.Info("repeated")
+		log.Error("failed", errors.New("boom"))
+	}
+	DisableSampling()
+
+	// entries 1, 2, 5 and 8 are kept
+	if n := observer.FilterMessage("repeated").Len(); n != 4 {
+		t.Errorf("got %d sampled entries, want 4", n)
+	}
+	if n := observer.FilterLevel("ERROR").Len(); n != 10 {
+		t.Errorf("got %d error entries, want all 10 kept", n)
+	}
+
+	summary := observer.FilterMessage("sampling_summary").All()
+	if len(summary) != 1 {
+		t.Fatalf("got %d sampling summaries, want 1", len(summary))
+	}
+	sampling := summary[0].Fields["data"].(map[string]interface{})["sampling"].(map[string]interface{})
+	if sampling["total_suppressed"] != float64(6) {
+		t.Errorf("got total_suppressed %v, want 6", sampling["total_suppressed"])
+	}
+}
+
+func TestSamplingTagRates(t *testing.T) {
+	observer := ReplaceGlobalsForTest(t)
+	SetSampling(SamplingConfig{TagRates: map[string]float64{"NOISY": 0, "KEPT": 1}})
+
+	noisy := NewLog("NOISY")
+	kept := NewLog("KEPT")
+	for i := 0; i < 5; i++ {
+		noisy.Info("message")
+		kept.Info("message")
+	}
+	noisy.Error("failed", errors.New("boom"))
+
+	if n := observer.FilterTag("NOISY").Len(); n != 1 {
+		t.Errorf("got %d NOISY entries, want only the error", n)
+	}
+	if n := observer.FilterTag("KEPT").Len(); n != 5 {
+		t.Errorf("got %d KEPT entries, want 5", n)
+	}
+}
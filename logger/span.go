@@ -0,0 +1,158 @@
+package logger
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Span is an operation of a request, identified by a span ID under the tracker ID of the request.
+// Log lines of a logger set with SetSpan or SetContext carry its span_id and parent_span_id,
+// so the call tree of a request can be rebuilt from the logs.
+type Span struct {
+	id        string
+	parentID  string
+	trackerID string
+	name      string
+	start     time.Time
+
+	mutex      sync.Mutex
+	end        time.Time
+	attributes map[string]interface{}
+	err        error
+}
+
+type spanKey struct{}
+
+// spanLogging enables the SPAN line logged by Span.End, see SetSpanLogging.
+var spanLogging atomic.Bool
+
+// SetSpanLogging makes Span.End log a SPAN line with the name, attributes and duration of the span.
+// It is disabled by default: the lines logged within a span already carry span_id and parent_span_id.
+func SetSpanLogging(enabled bool) {
+	spanLogging.Store(enabled)
+}
+
+// StartSpan starts a span named name, child of the span of ctx if any, and returns ctx carrying it.
+// The tracker ID of the span is the one of the parent span, or the "tracker_id" value of ctx.
+func StartSpan(ctx context.Context, name string) (context.Context, *Span) {
+	return StartSpanAt(ctx, name, time.Now())
+}
+
+// StartSpanAt is StartSpan for an operation which started at start, e.g. reported once completed.
+func StartSpanAt(ctx context.Context, name string, start time.Time) (context.Context, *Span) {
+	span := &Span{
+		id:    newSpanID(),
+		name:  name,
+		start: start,
+	}
+	if parent := SpanFromContext(ctx); parent != nil {
+		span.parentID = parent.id
+		span.trackerID = parent.trackerID
+	}
+	if trackerID, ok := ctx.Value("tracker_id").(string); ok && trackerID != "" {
+		span.trackerID = trackerID
+	}
+
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// SpanFromContext returns the span of ctx, nil when none.
+func SpanFromContext(ctx context.Context) *Span {
+	if ctx == nil {
+		return nil
+	}
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+// ID returns the span ID, 16 hex characters.
+func (s *Span) ID() string {
+	return s.id
+}
+
+// ParentID returns the span ID of the parent span, empty for a root span.
+func (s *Span) ParentID() string {
+	return s.parentID
+}
+
+// TrackerID returns the tracker ID the span belongs to.
+func (s *Span) TrackerID() string {
+	return s.trackerID
+}
+
+// Name returns the name of the span.
+func (s *Span) Name() string {
+	return s.name
+}
+
+// SetAttribute sets an attribute logged by End.
+func (s *Span) SetAttribute(key string, value interface{}) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.attributes == nil {
+		s.attributes = make(map[string]interface{})
+	}
+	s.attributes[key] = value
+}
+
+// SetError marks the span as failed, End then logs at error level.
+func (s *Span) SetError(err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.err = err
+}
+
+// End ends the span and, when SetSpanLogging is enabled, logs it with the SPAN tag.
+// Later calls only return the duration.
+func (s *Span) End() time.Duration {
+	s.mutex.Lock()
+	if !s.end.IsZero() {
+		s.mutex.Unlock()
+		return s.end.Sub(s.start)
+	}
+	s.end = time.Now()
+	if !spanLogging.Load() {
+		s.mutex.Unlock()
+		return s.end.Sub(s.start)
+	}
+	attributes := make(map[string]interface{}, len(s.attributes))
+	for k, v := range s.attributes {
+		attributes[k] = v
+	}
+	err := s.err
+	s.mutex.Unlock()
+
+	log := NewLog("SPAN")
+	log.SetSpan(s)
+	log.timerStart, log.timerEnd = s.start, s.end
+
+	level := InfoLevel
+	if err != nil {
+		level = ErrorLevel
+	}
+	level, logParams := log.newLogParams(level, s.name, map[string]interface{}{
+		"__gologger__": 1,
+		"span": map[string]interface{}{
+			"name":       s.name,
+			"attributes": attributes,
+		},
+	}, err)
+	write(level, logParams)
+
+	return s.end.Sub(s.start)
+}
+
+func newSpanID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%016x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
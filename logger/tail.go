@@ -0,0 +1,254 @@
+package logger
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+type (
+	// TailSamplingConfig defines how entries are buffered per tracker_id and kept or dropped once the request completes.
+	TailSamplingConfig struct {
+		// KeepLevel keeps every entry of a tracker ID having an entry at or above this level.
+		// Optional. Default value ErrorLevel.
+		KeepLevel Level
+
+		// SlowThreshold keeps every entry of a tracker ID whose request took longer.
+		// Optional. Default value 0 (disabled).
+		SlowThreshold time.Duration
+
+		// Rate is the probability of keeping the entries of the other tracker IDs, from 0 to 1.
+		// Optional. Default value 0 (drop them all).
+		Rate float64
+
+		// MaxEntries is the number of entries buffered per tracker ID, the tracker ID is decided when reached.
+		// Optional. Default value 500.
+		MaxEntries int
+
+		// MaxTrackerIDs is the number of tracker IDs buffered at once, the oldest is decided when reached.
+		// Optional. Default value 10000.
+		MaxTrackerIDs int
+
+		// Timeout decides tracker IDs never completed (orphaned) after this duration.
+		// Optional. Default value 1 minute.
+		Timeout time.Duration
+	}
+
+	tailEntry struct {
+		level     Level
+		logParams map[string]interface{}
+	}
+
+	tailTrace struct {
+		entries []tailEntry
+		keep    bool
+		start   time.Time
+	}
+
+	tailDecision struct {
+		keep bool
+		at   time.Time
+	}
+
+	tailSampling struct {
+		config  TailSamplingConfig
+		traces  map[string]*tailTrace
+		order   []string
+		decided map[string]tailDecision
+		stop    chan struct{}
+		mutex   sync.Mutex
+	}
+)
+
+var (
+	// DefaultTailSamplingConfig is the default tail sampling config.
+	DefaultTailSamplingConfig = TailSamplingConfig{
+		KeepLevel:     ErrorLevel,
+		SlowThreshold: 0,
+		Rate:          0,
+		MaxEntries:    500,
+		MaxTrackerIDs: 10000,
+		Timeout:       time.Minute,
+	}
+
+	tailSampler      *tailSampling
+	tailSamplerMutex sync.RWMutex
+)
+
+// SetTailSampling buffers entries having a tracker_id until CompleteTrackerID is called for it,
+// then writes or drops them all at once. It replaces the previous tail sampling config.
+func SetTailSampling(config TailSamplingConfig) {
+	if config.KeepLevel == 0 {
+		config.KeepLevel = DefaultTailSamplingConfig.KeepLevel
+	}
+	if config.MaxEntries <= 0 {
+		config.MaxEntries = DefaultTailSamplingConfig.MaxEntries
+	}
+	if config.MaxTrackerIDs <= 0 {
+		config.MaxTrackerIDs = DefaultTailSamplingConfig.MaxTrackerIDs
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTailSamplingConfig.Timeout
+	}
+
+	t := &tailSampling{
+		config:  config,
+		traces:  make(map[string]*tailTrace),
+		decided: make(map[string]tailDecision),
+		stop:    make(chan struct{}),
+	}
+	go t.sweep()
+
+	replaceTailSampler(t)
+}
+
+// DisableTailSampling writes the buffered entries and stops buffering.
+func DisableTailSampling() {
+	replaceTailSampler(nil)
+}
+
+func replaceTailSampler(t *tailSampling) {
+	tailSamplerMutex.Lock()
+	previous := tailSampler
+	tailSampler = t
+	tailSamplerMutex.Unlock()
+
+	if previous != nil {
+		close(previous.stop)
+		previous.mutex.Lock()
+		traces := previous.traces
+		previous.traces = make(map[string]*tailTrace)
+		previous.order = nil
+		previous.mutex.Unlock()
+		for _, trace := range traces {
+			flushTrace(trace.entries, true)
+		}
+	}
+}
+
+// CompleteTrackerID signals that the request of trackerID completed, its buffered entries are written
+// when failed, slow or sampled in, and dropped otherwise.
+func CompleteTrackerID(trackerID string, failed bool, elapsed time.Duration) {
+	tailSamplerMutex.RLock()
+	t := tailSampler
+	tailSamplerMutex.RUnlock()
+	if t == nil || trackerID == "" {
+		return
+	}
+
+	t.mutex.Lock()
+	entries, keep := t.decide(trackerID, failed || (t.config.SlowThreshold > 0 && elapsed > t.config.SlowThreshold))
+	t.mutex.Unlock()
+
+	flushTrace(entries, keep)
+}
+
+// bufferTail reports whether the entry was taken by the tail sampling stage.
+func bufferTail(level Level, logParams map[string]interface{}) bool {
+	tailSamplerMutex.RLock()
+	t := tailSampler
+	tailSamplerMutex.RUnlock()
+
+	trackerID, _ := logParams["tracker_id"].(string)
+	if t == nil || trackerID == "" {
+		return false
+	}
+
+	t.mutex.Lock()
+	if decision, ok := t.decided[trackerID]; ok {
+		t.mutex.Unlock()
+		// late entry of a decided tracker ID follows the decision, except fatal entries which must exit
+		return level != FatalLevel && !decision.keep
+	}
+
+	trace, ok := t.traces[trackerID]
+	if !ok {
+		trace = &tailTrace{start: time.Now()}
+		t.traces[trackerID] = trace
+		t.order = append(t.order, trackerID)
+	}
+	trace.entries = append(trace.entries, tailEntry{level: level, logParams: logParams})
+	if level >= t.config.KeepLevel {
+		trace.keep = true
+	}
+
+	var flushes []func()
+	if level == FatalLevel || len(trace.entries) >= t.config.MaxEntries {
+		entries, keep := t.decide(trackerID, level == FatalLevel)
+		flushes = append(flushes, func() { flushTrace(entries, keep) })
+	}
+	for len(t.traces) > t.config.MaxTrackerIDs {
+		entries, keep := t.decide(t.order[0], false)
+		flushes = append(flushes, func() { flushTrace(entries, keep) })
+	}
+	t.mutex.Unlock()
+
+	for _, flush := range flushes {
+		flush()
+	}
+	return true
+}
+
+// decide removes the trace of trackerID and returns its entries and whether they are kept. It must be called with the lock held.
+func (t *tailSampling) decide(trackerID string, keep bool) ([]tailEntry, bool) {
+	trace, ok := t.traces[trackerID]
+	if !ok {
+		return nil, false
+	}
+	delete(t.traces, trackerID)
+	for i, id := range t.order {
+		if id == trackerID {
+			t.order = append(t.order[:i], t.order[i+1:]...)
+			break
+		}
+	}
+
+	keep = keep || trace.keep || (t.config.Rate > 0 && rand.Float64() < t.config.Rate)
+	t.decided[trackerID] = tailDecision{keep: keep, at: time.Now()}
+
+	return trace.entries, keep
+}
+
+// sweep decides orphaned tracker IDs and forgets old decisions.
+func (t *tailSampling) sweep() {
+	ticker := time.NewTicker(t.config.Timeout / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			var flushes [][]tailEntry
+			t.mutex.Lock()
+			now := time.Now()
+			for _, trackerID := range append([]string{}, t.order...) {
+				if now.Sub(t.traces[trackerID].start) < t.config.Timeout {
+					break
+				}
+				if entries, keep := t.decide(trackerID, false); keep {
+					flushes = append(flushes, entries)
+				}
+			}
+			for trackerID, decision := range t.decided {
+				if now.Sub(decision.at) >= t.config.Timeout {
+					delete(t.decided, trackerID)
+				}
+			}
+			t.mutex.Unlock()
+
+			for _, entries := range flushes {
+				flushTrace(entries, true)
+			}
+		case <-t.stop:
+			return
+		}
+	}
+}
+
+func flushTrace(entries []tailEntry, keep bool) {
+	if !keep {
+		return
+	}
+	for _, entry := range entries {
+		emit(entry.level, entry.logParams)
+	}
+}
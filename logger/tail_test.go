@@ -0,0 +1,58 @@
+package logger
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTailSamplingDropsCompletedTrackerID(t *testing.T) {
+	observer := ReplaceGlobalsForTest(t)
+	SetTailSampling(TailSamplingConfig{})
+
+	log := NewLog("TEST")
+	log.SetTrackerID("dropped")
+	log.Info("buffered")
+	CompleteTrackerID("dropped", false, time.Millisecond)
+	log.Info("late")
+
+	log.SetTrackerID("kept")
+	log.Info("buffered")
+	CompleteTrackerID("kept", true, time.Millisecond)
+
+	if n := observer.Filter(func(e ObservedEntry) bool { return e.TrackerID == "dropped" }).Len(); n != 0 {
+		t.Errorf("got %d entries of a dropped tracker ID, want 0", n)
+	}
+	if n := observer.Filter(func(e ObservedEntry) bool { return e.TrackerID == "kept" }).Len(); n != 1 {
+		t.Errorf("got %d entries of a failed tracker ID, want 1", n)
+	}
+}
+
+func TestTailSamplingFatalAfterDropDecision(t *testing.T) {
+	if os.Getenv("GOLOGGER_TEST_FATAL") == "1" {
+		SetTailSampling(TailSamplingConfig{})
+		log := NewLog("TEST")
+		log.SetTrackerID("dropped")
+		log.Info("buffered")
+		CompleteTrackerID("dropped", false, time.Millisecond)
+		log.Fatal("fatal")
+		os.Stdout.WriteString("after fatal\n")
+		return
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestTailSamplingFatalAfterDropDecision$")
+	cmd.Env = append(os.Environ(), "GOLOGGER_TEST_FATAL=1")
+	out, err := cmd.CombinedOutput()
+
+	if exitErr, ok := err.(*exec.ExitError); !ok || exitErr.ExitCode() != 1 {
+		t.Fatalf("got exit error %v, want exit code 1, output:\n%s", err, out)
+	}
+	if !strings.Contains(string(out), `"message":"fatal"`) {
+		t.Errorf("fatal entry not written, output:\n%s", out)
+	}
+	if strings.Contains(string(out), "after fatal") {
+		t.Errorf("process kept running after Fatal, output:\n%s", out)
+	}
+}
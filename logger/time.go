@@ -0,0 +1,134 @@
+package logger
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// TimeFormat possible named values of Config.TimeFormat, any other value is used as a Go layout.
+const (
+	RFC3339TimeFormat     = "rfc3339"
+	RFC3339NanoTimeFormat = "rfc3339nano"
+	UnixTimeFormat        = "unix"
+	UnixMilliTimeFormat   = "unix_ms"
+	UnixNanoTimeFormat    = "unix_nano"
+)
+
+// parseTimeFormat returns the Go layout of a time format, or the named unix format.
+func parseTimeFormat(format string) (string, error) {
+	switch strings.ToLower(format) {
+	case "", RFC3339NanoTimeFormat:
+		return time.RFC3339Nano, nil
+	case RFC3339TimeFormat:
+		return time.RFC3339, nil
+	case UnixTimeFormat, UnixMilliTimeFormat, UnixNanoTimeFormat:
+		return strings.ToLower(format), nil
+	}
+	if !strings.ContainsAny(format, "0123456789") || !roundTrips(format) {
+		return "", fmt.Errorf("unknown time format %q", format)
+	}
+	return format, nil
+}
+
+// layoutReference has no component equal to its zero value, a layout reading one of them back wrongly is rejected.
+var layoutReference = time.Date(2009, time.November, 17, 20, 34, 58, 651387237, time.UTC)
+
+// roundTrips reports whether layoutReference formatted with layout parses back to the components the layout holds,
+// e.g. "RFC1123" is formatted "RFC1111178" and read back as 8 o'clock.
+func roundTrips(layout string) bool {
+	parsed, err := time.Parse(layout, layoutReference.Format(layout))
+	if err != nil {
+		return false
+	}
+	parsed = parsed.UTC()
+
+	same := func(got, want, zero int) bool { return got == want || got == zero }
+	if !same(parsed.Year(), layoutReference.Year(), 0) ||
+		!same(int(parsed.Month()), int(layoutReference.Month()), 1) ||
+		!same(parsed.Day(), layoutReference.Day(), 1) ||
+		!same(parsed.Hour(), layoutReference.Hour(), 0) ||
+		!same(parsed.Minute(), layoutReference.Minute(), 0) ||
+		!same(parsed.Second(), layoutReference.Second(), 0) {
+		return false
+	}
+	// fractional seconds are truncated to the digits of the layout
+	for unit := int(time.Second); unit >= 1; unit /= 10 {
+		if parsed.Nanosecond() == layoutReference.Nanosecond()-layoutReference.Nanosecond()%unit {
+			return true
+		}
+	}
+	return false
+}
+
+// parseTimeZone accepts "Local", "UTC", an IANA zone name or a fixed offset such as "+07:00".
+func parseTimeZone(zone string) (*time.Location, error) {
+	if len(zone) == 6 && (zone[0] == '+' || zone[0] == '-') && zone[3] == ':' {
+		t, err := time.Parse("-07:00", zone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time zone offset %q", zone)
+		}
+		_, offset := t.Zone()
+		return time.FixedZone("UTC"+zone, offset), nil
+	}
+	return time.LoadLocation(zone)
+}
+
+// formatTime formats t with the configured time format and zone, unix formats are numbers.
+func (s settings) formatTime(t time.Time) interface{} {
+	switch s.timeLayout {
+	case UnixTimeFormat:
+		return t.Unix()
+	case UnixMilliTimeFormat:
+		return t.UnixMilli()
+	case UnixNanoTimeFormat:
+		return t.UnixNano()
+	}
+	return t.In(s.location).Format(s.timeLayout)
+}
+
+// formatTimes returns a copy of value where time.Time values at any depth are formatted with formatTime.
+func (s settings) formatTimes(value interface{}) interface{} {
+	switch v := value.(type) {
+	case time.Time:
+		return s.formatTime(v)
+	case *time.Time:
+		if v == nil {
+			return v
+		}
+		return s.formatTime(*v)
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			copied[key] = s.formatTimes(val)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(v))
+		for i, val := range v {
+			copied[i] = s.formatTimes(val)
+		}
+		return copied
+	}
+	return value
+}
+
+// ParseTime reads a time field of a decoded log line, written in RFC3339 or as unix seconds, milliseconds or nanoseconds.
+func ParseTime(value interface{}) (time.Time, bool) {
+	switch v := value.(type) {
+	case string:
+		t, err := time.Parse(time.RFC3339Nano, v)
+		return t, err == nil
+	case float64:
+		switch abs := math.Abs(v); {
+		case abs < 1e11:
+			return time.Unix(0, int64(v*1e9)), true
+		case abs < 1e14:
+			return time.UnixMilli(int64(v)), true
+		default:
+			return time.Unix(0, int64(v)), true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,32 @@
+package logger
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTimeFormat(t *testing.T) {
+	valid := map[string]string{
+		"":                        time.RFC3339Nano,
+		"RFC3339":                 time.RFC3339,
+		"unix_ms":                 UnixMilliTimeFormat,
+		"2006-01-02":              "2006-01-02",
+		"15:04:05.000":            "15:04:05.000",
+		"02/01/2006 03:04:05 PM":  "02/01/2006 03:04:05 PM",
+		time.RFC1123:              time.RFC1123,
+		time.Kitchen:              time.Kitchen,
+		"2006-01-02T15:04:05.999": "2006-01-02T15:04:05.999",
+	}
+	for format, want := range valid {
+		if got, err := parseTimeFormat(format); err != nil || got != want {
+			t.Errorf("parseTimeFormat(%q): got %q and error %v, want %q", format, got, err, want)
+		}
+	}
+
+	// constant names and layouts reading the time back wrongly, e.g. a 12-hour clock without AM/PM
+	for _, format := range []string{"RFC1123", "RFC3339Milli", "yyyy-mm-dd", "2006-01-02 03:04"} {
+		if got, err := parseTimeFormat(format); err == nil {
+			t.Errorf("parseTimeFormat(%q): got %q, want an error", format, got)
+		}
+	}
+}
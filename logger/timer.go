@@ -0,0 +1,160 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Timer measures a named operation, End logs it once with its processing_time.
+// Timers started from a Timer are nested under it, the "timer" field of the log line holds the name, path and depth.
+type Timer struct {
+	log    newLog
+	name   string
+	parent *Timer
+	start  time.Time
+
+	mutex sync.Mutex
+	end   time.Time
+}
+
+// StartTimer starts a timer named name, logged with the tag and tracker ID of newLog.
+func (newLog *newLog) StartTimer(name string) *Timer {
+	log := *newLog
+	log.timerStart = time.Time{}
+	return &Timer{log: log, name: name, start: time.Now()}
+}
+
+// StartTimer starts a timer nested under t.
+func (t *Timer) StartTimer(name string) *Timer {
+	return &Timer{log: t.log, name: name, parent: t, start: time.Now()}
+}
+
+// Name returns the name of the timer.
+func (t *Timer) Name() string {
+	return t.name
+}
+
+// Path returns the names from the root timer to t joined by "/", e.g. "checkout/charge_card".
+func (t *Timer) Path() string {
+	if t.parent == nil {
+		return t.name
+	}
+	return t.parent.Path() + "/" + t.name
+}
+
+// Elapsed returns the duration since the timer started, or its final duration once ended.
+func (t *Timer) Elapsed() time.Duration {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if !t.end.IsZero() {
+		return t.end.Sub(t.start)
+	}
+	return time.Since(t.start)
+}
+
+// End stops the timer and logs it at info level, later calls only return the duration.
+func (t *Timer) End() time.Duration {
+	log, duration, first := t.stop()
+	if first {
+		level, logParams := log.newLogParams(InfoLevel, t.name, nil, nil)
+		write(level, logParams)
+	}
+	return duration
+}
+
+// EndWithData stops the timer and logs it at info level with data, later calls only return the duration.
+func (t *Timer) EndWithData(data map[string]interface{}) time.Duration {
+	log, duration, first := t.stop()
+	if first {
+		level, logParams := log.newLogParams(InfoLevel, t.name, data, nil)
+		write(level, logParams)
+	}
+	return duration
+}
+
+// stop records the end of the timer and returns the logger of the ending log line and the final duration,
+// read under the lock as another goroutine may be ending the timer. first is false when already ended.
+func (t *Timer) stop() (log newLog, duration time.Duration, first bool) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if !t.end.IsZero() {
+		return log, t.end.Sub(t.start), false
+	}
+	t.end = time.Now()
+
+	log = t.log
+	log.timer = t
+	log.timerStart, log.timerEnd = t.start, t.end
+	return log, t.end.Sub(t.start), true
+}
+
+func (t *Timer) fields() map[string]interface{} {
+	fields := map[string]interface{}{
+		"name":  t.name,
+		"path":  t.Path(),
+		"depth": 0,
+	}
+	if t.parent != nil {
+		fields["parent"] = t.parent.name
+		fields["depth"] = strings.Count(fields["path"].(string), "/")
+	}
+	return fields
+}
+
+// durationUnits are the accepted values of Config.DurationUnit.
+var durationUnits = map[string]time.Duration{
+	"s":  time.Second,
+	"ms": time.Millisecond,
+	"us": time.Microsecond,
+	"µs": time.Microsecond,
+	"ns": time.Nanosecond,
+}
+
+func parseDurationUnit(name string) (time.Duration, error) {
+	unit, ok := durationUnits[strings.ToLower(name)]
+	if !ok {
+		return time.Millisecond, fmt.Errorf("unknown duration unit %q, expected \"s\", \"ms\", \"us\" or \"ns\"", name)
+	}
+	return unit, nil
+}
+
+func durationUnitName(unit time.Duration) string {
+	switch unit {
+	case time.Second:
+		return "s"
+	case time.Microsecond:
+		return "us"
+	case time.Nanosecond:
+		return "ns"
+	}
+	return "ms"
+}
+
+// formatDuration returns d in the configured unit, keeping the fraction (0.9ms stays 0.9).
+func (s settings) formatDuration(d time.Duration) float64 {
+	if s.durationUnit <= 0 {
+		return float64(d) / float64(time.Millisecond)
+	}
+	return float64(d) / float64(s.durationUnit)
+}
+
+// ParseProcessingTime reads the processing_time of a decoded log line, written in the unit of processing_time_unit
+// or in milliseconds when absent.
+func ParseProcessingTime(fields map[string]interface{}) (time.Duration, bool) {
+	value, ok := fields["processing_time"].(float64)
+	if !ok {
+		return 0, false
+	}
+
+	unit := time.Millisecond
+	if name, ok := fields["processing_time_unit"].(string); ok {
+		if u, ok := durationUnits[name]; ok {
+			unit = u
+		}
+	}
+	return time.Duration(value * float64(unit)), true
+}
@@ -0,0 +1,114 @@
+package logger
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTimerNestedPaths(t *testing.T) {
+	observer := ReplaceGlobalsForTest(t)
+
+	log := NewLog("TEST")
+	checkout := log.StartTimer("checkout")
+	charge := checkout.StartTimer("charge_card")
+	call := charge.StartTimer("call_bank")
+	call.End()
+	charge.End()
+	checkout.End()
+
+	want := map[string]map[string]interface{}{
+		"call_bank":   {"path": "checkout/charge_card/call_bank", "depth": float64(2), "parent": "charge_card"},
+		"charge_card": {"path": "checkout/charge_card", "depth": float64(1), "parent": "checkout"},
+		"checkout":    {"path": "checkout", "depth": float64(0)},
+	}
+	for name, fields := range want {
+		entries := observer.FilterMessage(name).All()
+		if len(entries) != 1 {
+			t.Fatalf("got %d %s entries, want 1", len(entries), name)
+		}
+		timer := entries[0].Fields["timer"].(map[string]interface{})
+		for k, v := range fields {
+			if timer[k] != v {
+				t.Errorf("%s: got timer %s %v, want %v", name, k, timer[k], v)
+			}
+		}
+	}
+}
+
+func TestTimerEndConcurrently(t *testing.T) {
+	observer := ReplaceGlobalsForTest(t)
+
+	log := NewLog("TEST")
+	timer := log.StartTimer("shared")
+
+	durations := make([]time.Duration, 8)
+	var wg sync.WaitGroup
+	for i := range durations {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if i%2 == 0 {
+				durations[i] = timer.End()
+			} else {
+				durations[i] = timer.EndWithData(map[string]interface{}{"i": i})
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	for _, d := range durations {
+		if d != durations[0] || d != timer.Elapsed() {
+			t.Fatalf("got durations %v, want all equal to Elapsed %v", durations, timer.Elapsed())
+		}
+	}
+	if n := observer.FilterMessage("shared").Len(); n != 1 {
+		t.Errorf("got %d entries, want the timer logged once", n)
+	}
+}
+
+func TestProcessingTimeUnit(t *testing.T) {
+	tests := []struct {
+		unit     string
+		want     float64
+		wantUnit interface{}
+	}{
+		{"", 0.9, nil},
+		{"ms", 0.9, nil},
+		{"us", 900, "us"},
+		{"s", 0.0009, "s"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.unit, func(t *testing.T) {
+			observer := ReplaceGlobalsForTest(t)
+			config := DefaultConfig
+			config.DurationUnit = tt.unit
+			if err := Configure(config); err != nil {
+				t.Fatal(err)
+			}
+			SetOutput(observer)
+
+			start := time.Now()
+			log := NewLog("TEST")
+			log.SetTimerStart(start)
+			log.timerEnd = start.Add(900 * time.Microsecond)
+			log.Info("measured")
+
+			fields := observer.All()[0].Fields
+			if fields["processing_time"] != tt.want {
+				t.Errorf("got processing_time %v, want %v", fields["processing_time"], tt.want)
+			}
+			if fields["processing_time_unit"] != tt.wantUnit {
+				t.Errorf("got processing_time_unit %v, want %v", fields["processing_time_unit"], tt.wantUnit)
+			}
+			if d, ok := ParseProcessingTime(fields); !ok || d != 900*time.Microsecond {
+				t.Errorf("ParseProcessingTime: got %v, want 900µs", d)
+			}
+		})
+	}
+
+	ReplaceGlobalsForTest(t)
+	if err := Configure(Config{DurationUnit: "minutes"}); err == nil {
+		t.Error("got no error for an unknown duration unit")
+	}
+}
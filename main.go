@@ -30,4 +30,11 @@ func main() {
 	// fmt.Printf("-- delay %v seconds --\n", timeDelay)
 	time.Sleep(time.Duration(1) * time.Second)
 	log.InfoWithData("test map3", mappp)
+
+	// measure nested operations, each End logs one line with its processing_time
+	timer := log.StartTimer("checkout")
+	chargeTimer := timer.StartTimer("charge_card")
+	time.Sleep(time.Duration(500) * time.Microsecond)
+	chargeTimer.EndWithData(mappp)
+	timer.End()
 }
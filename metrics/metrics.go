@@ -0,0 +1,325 @@
+// Package metrics derives Prometheus metrics from go-logger entries (request, query and panic logs)
+// and exposes them in the Prometheus text format, without any dependency beyond the standard library.
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	goLogger "github.com/pobyzaarif/go-logger/logger"
+)
+
+type (
+	// Config defines the config for Collector.
+	Config struct {
+		// Namespace prefixes the metric names, e.g. "gologger_log_lines_total".
+		// Optional. Default value "gologger".
+		Namespace string
+
+		// Buckets are the upper bounds, in seconds, of the duration histograms.
+		// Optional. Default value DefaultBuckets.
+		Buckets []float64
+
+		// MaxSeries is the number of label sets kept per metric, the entries of new label sets
+		// beyond it are counted with every label set to "other".
+		// Optional. Default value 1000.
+		MaxSeries int
+	}
+
+	// Collector derives metrics from the entries it receives and serves them as an http.Handler.
+	// Durations and panics come from a goLogger.Listener, so they don't depend on the log level,
+	// the log lines come from a goLogger.Hook:
+	//
+	//	collector := metrics.New(metrics.DefaultConfig)
+	//	goLogger.AddListener(collector.Listener)
+	//	goLogger.AddHook(collector.Hook)
+	//	e.GET("/metrics", echo.WrapHandler(collector))
+	Collector struct {
+		config   Config
+		families []*family
+
+		logLines         *family
+		inboundDuration  *family
+		outboundDuration *family
+		dbDuration       *family
+		panics           *family
+	}
+)
+
+// tags of the entries logged by the go-logger integrations
+const (
+	inboundTag  = "INBOUND_REQUEST"
+	outboundTag = "OUTBOUND_REQUEST"
+	gormTag     = "GORM_QUERY"
+	mongoTag    = "MONGO_QUERY"
+	panicTag    = "PANIC"
+)
+
+var (
+	// DefaultBuckets are the default histogram buckets of the Prometheus client, in seconds.
+	DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+	// DefaultConfig is the default Collector config.
+	DefaultConfig = Config{
+		Namespace: "gologger",
+		Buckets:   DefaultBuckets,
+		MaxSeries: 1000,
+	}
+)
+
+// New creates a Collector.
+func New(config Config) *Collector {
+	if config.Namespace == "" {
+		config.Namespace = DefaultConfig.Namespace
+	}
+	if len(config.Buckets) == 0 {
+		config.Buckets = DefaultConfig.Buckets
+	}
+	if config.MaxSeries <= 0 {
+		config.MaxSeries = DefaultConfig.MaxSeries
+	}
+
+	c := &Collector{config: config}
+	c.logLines = c.newFamily("log_lines_total", "Log lines by level and tag.", counterKind, "level", "tag")
+	c.inboundDuration = c.newFamily("http_server_request_duration_seconds", "Duration of the inbound HTTP requests.", histogramKind, "method", "route", "status")
+	c.outboundDuration = c.newFamily("http_client_request_duration_seconds", "Duration of the outbound HTTP requests, status 0 when no response was received.", histogramKind, "method", "host", "status")
+	c.dbDuration = c.newFamily("db_query_duration_seconds", "Duration of the database queries.", histogramKind, "system", "operation", "status")
+	c.panics = c.newFamily("panics_total", "Recovered panics.", counterKind)
+
+	return c
+}
+
+func (c *Collector) newFamily(name, help string, kind kind, labels ...string) *family {
+	f := &family{
+		name:      c.config.Namespace + "_" + name,
+		help:      help,
+		kind:      kind,
+		labels:    labels,
+		buckets:   c.config.Buckets,
+		maxSeries: c.config.MaxSeries,
+		series:    make(map[string]*series),
+	}
+	c.families = append(c.families, f)
+	return f
+}
+
+// Hook is a goLogger.Hook counting the log lines, it never drops the entry.
+func (c *Collector) Hook(entry *goLogger.Entry) bool {
+	c.logLines.inc(entry.Level.String(), entry.Tag)
+	return true
+}
+
+// Listener is a goLogger.Listener updating the duration histograms and the panic counter,
+// it receives the entries whatever the log level and the hooks.
+func (c *Collector) Listener(entry goLogger.Entry) {
+	seconds := entry.Duration.Seconds()
+	switch entry.Tag {
+	case inboundTag:
+		if net, ok := entry.Data["net"].(map[string]interface{}); ok {
+			route := stringValue(net["route"])
+			if route == "" {
+				route = "unmatched"
+			}
+			c.inboundDuration.observe(seconds, stringValue(net["method"]), route, stringValue(net["response_http_code"]))
+		}
+	case outboundTag:
+		// entries logged before sending, e.g. rejected by the rate limiter, have no timing
+		if net, ok := entry.Data["net"].(map[string]interface{}); ok && net["timing"] != nil {
+			c.outboundDuration.observe(seconds, stringValue(net["method"]), stringValue(net["host"]), stringValue(net["response_http_code"]))
+		}
+	case gormTag, mongoTag:
+		if db, ok := entry.Data["db"].(map[string]interface{}); ok {
+			system := "gorm"
+			if entry.Tag == mongoTag {
+				system = "mongo"
+			}
+			operation := stringValue(db["operation"])
+			if operation == "" {
+				operation = sqlOperation(stringValue(db["query"]))
+			}
+			status := "ok"
+			if entry.Error != nil {
+				status = "error"
+			}
+			c.dbDuration.observe(seconds, system, operation, status)
+		}
+	case panicTag:
+		c.panics.inc()
+	}
+}
+
+// ServeHTTP writes the metrics in the Prometheus text format.
+func (c *Collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	var sb strings.Builder
+	for _, f := range c.families {
+		f.write(&sb)
+	}
+	_, _ = w.Write([]byte(sb.String()))
+}
+
+// sqlOperation returns the first keyword of query in upper case, e.g. "SELECT".
+func sqlOperation(query string) string {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return "unknown"
+	}
+	operation := strings.ToUpper(strings.Trim(fields[0], "(;"))
+	for _, r := range operation {
+		if r < 'A' || r > 'Z' {
+			return "unknown"
+		}
+	}
+	return operation
+}
+
+func stringValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
+
+type (
+	kind int
+
+	// family is a metric with its series by label values.
+	family struct {
+		name      string
+		help      string
+		kind      kind
+		labels    []string
+		buckets   []float64
+		maxSeries int
+
+		mutex  sync.Mutex
+		series map[string]*series
+	}
+
+	series struct {
+		labelValues []string
+		count       uint64
+		sum         float64
+		// buckets are the cumulative counts of the histogram buckets
+		buckets []uint64
+	}
+)
+
+const (
+	counterKind kind = iota
+	histogramKind
+)
+
+// overflow is the label value of the entries beyond MaxSeries
+const overflow = "other"
+
+func (f *family) inc(labelValues ...string) {
+	f.observe(0, labelValues...)
+}
+
+func (f *family) observe(value float64, labelValues ...string) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	key := strings.Join(labelValues, "\xff")
+	s, ok := f.series[key]
+	if !ok {
+		if len(f.series) >= f.maxSeries {
+			for i := range labelValues {
+				labelValues[i] = overflow
+			}
+			key = strings.Join(labelValues, "\xff")
+			s, ok = f.series[key]
+		}
+		if !ok {
+			s = &series{labelValues: labelValues}
+			if f.kind == histogramKind {
+				s.buckets = make([]uint64, len(f.buckets))
+			}
+			f.series[key] = s
+		}
+	}
+
+	s.count++
+	if f.kind == histogramKind {
+		s.sum += value
+		for i, bound := range f.buckets {
+			if value <= bound {
+				s.buckets[i]++
+			}
+		}
+	}
+}
+
+// write writes the family in the Prometheus text format, series sorted by label values.
+func (f *family) write(sb *strings.Builder) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	kindName := "counter"
+	if f.kind == histogramKind {
+		kindName = "histogram"
+	}
+	fmt.Fprintf(sb, "# HELP %s %s\n# TYPE %s %s\n", f.name, f.help, f.name, kindName)
+
+	keys := make([]string, 0, len(f.series))
+	for key := range f.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		s := f.series[key]
+		labels := f.formatLabels(s.labelValues)
+		if f.kind == counterKind {
+			fmt.Fprintf(sb, "%s%s %d\n", f.name, wrapLabels(labels), s.count)
+			continue
+		}
+
+		for i, bound := range f.buckets {
+			fmt.Fprintf(sb, "%s_bucket%s %d\n", f.name, wrapLabels(appendLabel(labels, "le", formatFloat(bound))), s.buckets[i])
+		}
+		fmt.Fprintf(sb, "%s_bucket%s %d\n", f.name, wrapLabels(appendLabel(labels, "le", "+Inf")), s.count)
+		fmt.Fprintf(sb, "%s_sum%s %s\n", f.name, wrapLabels(labels), formatFloat(s.sum))
+		fmt.Fprintf(sb, "%s_count%s %d\n", f.name, wrapLabels(labels), s.count)
+	}
+}
+
+func (f *family) formatLabels(labelValues []string) string {
+	var labels string
+	for i, name := range f.labels {
+		labels = appendLabel(labels, name, labelValues[i])
+	}
+	return labels
+}
+
+func appendLabel(labels, name, value string) string {
+	label := name + `="` + labelEscaper.Replace(value) + `"`
+	if labels == "" {
+		return label
+	}
+	return labels + "," + label
+}
+
+func wrapLabels(labels string) string {
+	if labels == "" {
+		return ""
+	}
+	return "{" + labels + "}"
+}
+
+// labelEscaper escapes the characters of a label value as required by the text format.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
@@ -0,0 +1,130 @@
+package metrics
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	goLoggerGorm "github.com/pobyzaarif/go-logger/database/framework/gorm/logger"
+	goLoggerMongo "github.com/pobyzaarif/go-logger/database/framework/mongo-driver/logger"
+	goLogger "github.com/pobyzaarif/go-logger/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+	lg "gorm.io/gorm/logger"
+)
+
+func scrape(t *testing.T, collector *Collector) string {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	collector.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	return rec.Body.String()
+}
+
+func assertContains(t *testing.T, body string, lines ...string) {
+	t.Helper()
+
+	for _, line := range lines {
+		if !strings.Contains(body, line+"\n") {
+			t.Errorf("missing %q in:\n%s", line, body)
+		}
+	}
+}
+
+func install(t *testing.T, collector *Collector) {
+	t.Cleanup(goLogger.AddListener(collector.Listener))
+	t.Cleanup(goLogger.AddHook(collector.Hook))
+}
+
+func TestMongoQueryDuration(t *testing.T) {
+	goLogger.ReplaceGlobalsForTest(t)
+	collector := New(Config{Buckets: []float64{0.1, 1}})
+	install(t, collector)
+
+	monitor := goLoggerMongo.Monitor()
+	ctx := context.Background()
+	command, _ := bson.Marshal(bson.M{"find": "users"})
+
+	monitor.Started(ctx, &event.CommandStartedEvent{Command: command, CommandName: "find", RequestID: 1, ConnectionID: "c"})
+	monitor.Succeeded(ctx, &event.CommandSucceededEvent{CommandFinishedEvent: event.CommandFinishedEvent{
+		Duration: 300 * time.Millisecond, CommandName: "find", RequestID: 1, ConnectionID: "c",
+	}})
+	monitor.Started(ctx, &event.CommandStartedEvent{Command: command, CommandName: "find", RequestID: 2, ConnectionID: "c"})
+	monitor.Failed(ctx, &event.CommandFailedEvent{CommandFinishedEvent: event.CommandFinishedEvent{
+		Duration: 2 * time.Second, CommandName: "find", RequestID: 2, ConnectionID: "c",
+	}, Failure: "timeout"})
+
+	assertContains(t, scrape(t, collector),
+		`gologger_db_query_duration_seconds_bucket{system="mongo",operation="find",status="ok",le="0.1"} 0`,
+		`gologger_db_query_duration_seconds_bucket{system="mongo",operation="find",status="ok",le="1"} 1`,
+		`gologger_db_query_duration_seconds_bucket{system="mongo",operation="find",status="error",le="1"} 0`,
+		`gologger_db_query_duration_seconds_count{system="mongo",operation="find",status="error"} 1`,
+	)
+}
+
+func TestLogLinesAndPanics(t *testing.T) {
+	goLogger.ReplaceGlobalsForTest(t)
+	collector := New(DefaultConfig)
+	install(t, collector)
+
+	log := goLogger.NewLog("PANIC")
+	log.ErrorWithData("PANIC", map[string]interface{}{"__gologger__": 1, "panic": map[string]interface{}{}}, nil)
+	log = goLogger.NewLog("APP")
+	log.Info("a \"quoted\" message")
+	log.Info("again")
+
+	assertContains(t, scrape(t, collector),
+		`gologger_log_lines_total{level="INFO",tag="APP"} 2`,
+		`gologger_log_lines_total{level="ERROR",tag="PANIC"} 1`,
+		`gologger_panics_total 1`,
+	)
+}
+
+func TestMaxSeries(t *testing.T) {
+	goLogger.ReplaceGlobalsForTest(t)
+	collector := New(Config{MaxSeries: 1})
+	install(t, collector)
+
+	for _, tag := range []string{"A", "B", "C"} {
+		log := goLogger.NewLog(tag)
+		log.Info("x")
+	}
+
+	assertContains(t, scrape(t, collector),
+		`gologger_log_lines_total{level="INFO",tag="A"} 1`,
+		`gologger_log_lines_total{level="other",tag="other"} 2`,
+	)
+}
+
+func TestDurationsDoNotDependOnLogLevel(t *testing.T) {
+	goLogger.ReplaceGlobalsForTest(t)
+	config := goLogger.DefaultConfig
+	config.Level = "error"
+	config.Outputs = []string{"stderr"}
+	if err := goLogger.Configure(config); err != nil {
+		t.Fatal(err)
+	}
+	collector := New(Config{Buckets: []float64{1}})
+	install(t, collector)
+	// a hook dropping every entry hides nothing from the listener
+	t.Cleanup(goLogger.AddHook(func(entry *goLogger.Entry) bool { return false }))
+
+	inbound := goLogger.NewLog("INBOUND_REQUEST")
+	inbound.SetTimerStart(time.Now().Add(-100 * time.Millisecond))
+	inbound.InfoWithData("api_info", map[string]interface{}{"__gologger__": 1, "net": map[string]interface{}{
+		"method": "GET", "route": "/users/:id", "response_http_code": 200,
+	}})
+
+	gormLogger := goLoggerGorm.New(nil, goLoggerGorm.Config{LogLevel: lg.Warn})
+	gormLogger.Trace(context.Background(), time.Now(), func() (string, int64) { return "SELECT * FROM users", 1 }, nil)
+
+	assertContains(t, scrape(t, collector),
+		`gologger_http_server_request_duration_seconds_count{method="GET",route="/users/:id",status="200"} 1`,
+		`gologger_db_query_duration_seconds_count{system="gorm",operation="SELECT",status="ok"} 1`,
+	)
+	if body := scrape(t, collector); strings.Contains(body, "gologger_log_lines_total{") {
+		t.Errorf("log lines counted although none was written:\n%s", body)
+	}
+}
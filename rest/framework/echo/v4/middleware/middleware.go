@@ -1,7 +1,10 @@
 package middleware
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"net/http"
 	"path"
 	"strings"
 	"time"
@@ -26,13 +29,102 @@ func ServiceRequestTime(next echo.HandlerFunc) echo.HandlerFunc {
 	}
 }
 
+type (
+	// TrackerIDConfig defines the config for ServiceTrackerID middleware.
+	TrackerIDConfig struct {
+		// Header is the incoming header carrying the caller's tracker_id.
+		// Optional. Default value "X-Tracker-ID".
+		Header string
+
+		// DisableIncoming always generates a new tracker_id, ignoring the incoming header.
+		// Optional. Default value false.
+		DisableIncoming bool
+
+		// Generator generates a tracker_id when none is received.
+		// Optional. Default value uuid.New().String.
+		Generator func() string
+	}
+)
+
+var (
+	// DefaultTrackerIDConfig is the default ServiceTrackerID middleware config.
+	DefaultTrackerIDConfig = TrackerIDConfig{
+		Header:          goLoggerHttp.HeaderTrackerID,
+		DisableIncoming: false,
+		Generator:       func() string { return uuid.New().String() },
+	}
+)
+
+// ServiceTrackerID sets tracker_id from the incoming "X-Tracker-ID" header, or a new UUID when absent.
 func ServiceTrackerID(next echo.HandlerFunc) echo.HandlerFunc {
-	return func(c echo.Context) error {
-		c.Set("tracker_id", uuid.New().String())
-		return next(c)
+	return ServiceTrackerIDWithConfig(DefaultTrackerIDConfig)(next)
+}
+
+// ServiceTrackerIDWithConfig returns a ServiceTrackerID middleware with config.
+// Incoming W3C traceparent/tracestate headers are kept as "traceparent"/"tracestate".
+// The request context carries the tracker_id, traceparent, tracestate and the root span of the request, see goLogger.StartSpan.
+func ServiceTrackerIDWithConfig(config TrackerIDConfig) echo.MiddlewareFunc {
+	if config.Header == "" {
+		config.Header = DefaultTrackerIDConfig.Header
+	}
+	if config.Generator == nil {
+		config.Generator = DefaultTrackerIDConfig.Generator
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			trackerID := ""
+			if !config.DisableIncoming {
+				trackerID = validTrackerID(c.Request().Header.Get(config.Header))
+			}
+			if trackerID == "" {
+				trackerID = config.Generator()
+			}
+			c.Set("tracker_id", trackerID)
+
+			ctx := context.WithValue(c.Request().Context(), "tracker_id", trackerID)
+
+			traceParent := c.Request().Header.Get(goLoggerHttp.HeaderTraceParent)
+			if _, _, _, ok := goLoggerHttp.ParseTraceParent(traceParent); ok {
+				traceState := c.Request().Header.Get(goLoggerHttp.HeaderTraceState)
+				c.Set("traceparent", traceParent)
+				c.Set("tracestate", traceState)
+				// http/client.Call continues the incoming trace from the request context
+				ctx = context.WithValue(ctx, "traceparent", traceParent)
+				ctx = context.WithValue(ctx, "tracestate", traceState)
+			}
+
+			ctx, span := goLogger.StartSpan(ctx, c.Request().Method+" "+c.Path())
+			c.SetRequest(c.Request().WithContext(ctx))
+
+			err := next(c)
+			span.SetAttribute("response_http_code", c.Response().Status)
+			if err != nil {
+				span.SetError(err)
+			} else if c.Response().Status >= http.StatusInternalServerError {
+				span.SetError(errors.New(http.StatusText(c.Response().Status)))
+			}
+			span.End()
+
+			return err
+		}
 	}
 }
 
+// validTrackerID drops incoming values that are too long or contain non printable characters.
+func validTrackerID(trackerID string) string {
+	trackerID = strings.TrimSpace(trackerID)
+	if len(trackerID) > 128 {
+		return ""
+	}
+	for _, r := range trackerID {
+		if r < 0x21 || r > 0x7e {
+			return ""
+		}
+	}
+	return trackerID
+}
+
 func APILogHandler(c echo.Context, req, res []byte) {
 	c.Response().Header().Set(headerResponseTime, time.Now().Format(time.RFC3339Nano))
 	reqTime, err := time.Parse(time.RFC3339Nano, c.Request().Header.Get(headerRequestTime))
@@ -55,6 +147,12 @@ func APILogHandler(c echo.Context, req, res []byte) {
 		}
 	}
 
+	// route pattern, empty for requests matching no route to keep its cardinality bounded
+	route := c.Path()
+	if handler == "" || handler == "UndefinedRoute" {
+		route = ""
+	}
+
 	// Get Handler Name
 	dir, file := path.Split(handler)
 	fileStrings := strings.Split(file, ".")
@@ -62,15 +160,18 @@ func APILogHandler(c echo.Context, req, res []byte) {
 	funcHandler := strings.Replace(handler, packHandler+".", "", -1)
 
 	respHeader, _ := json.Marshal(c.Response().Header())
-	reqHeader := goLoggerHttp.DumpRequest(c.Request(), []string{"Authorization"})
+	reqHeader := goLoggerHttp.DumpRequest(c.Request(), goLoggerHttp.HiddenHeaders())
 
 	tranckerID, _ := c.Get("tracker_id").(string)
 	logger := goLogger.NewLog("INBOUND_REQUEST")
 	logger.SetTimerStart(reqTime)
 	logger.SetTrackerID(tranckerID)
+	logger.SetContext(c.Request().Context())
 	logger.InfoWithData("api_info", goLoggerHttp.NetworkLog(map[string]interface{}{
 		"handler":            funcHandler,
+		"route":              route,
 		"remote_ip":          c.RealIP(),
+		"scheme":             c.Scheme(),
 		"host":               c.Request().Host,
 		"method":             c.Request().Method,
 		"url":                c.Request().RequestURI,
@@ -80,4 +181,7 @@ func APILogHandler(c echo.Context, req, res []byte) {
 		"response":           string(res),
 		"response_http_code": c.Response().Status,
 	}))
+
+	// let tail sampling keep or drop the entries of this request
+	goLogger.CompleteTrackerID(tranckerID, c.Response().Status >= 500, time.Since(reqTime))
 }
@@ -0,0 +1,196 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pobyzaarif/go-logger/gologgertest"
+	goLoggerClient "github.com/pobyzaarif/go-logger/http/client"
+	goLogger "github.com/pobyzaarif/go-logger/logger"
+)
+
+func TestSpansRebuildCallTree(t *testing.T) {
+	downstream := gologgertest.NewServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	server := gologgertest.NewEchoServer(t, func(e *echo.Echo) {
+		e.GET("/orders/:id", func(c echo.Context) error {
+			request, _ := http.NewRequest(http.MethodGet, downstream.URL+"/stock", nil)
+			_, _ = goLoggerClient.Call(c.Request().Context(), request, 0, goLoggerClient.RawResponseBodyFormat, nil, nil)
+			return c.String(http.StatusOK, "ok")
+		})
+	})
+	goLogger.SetSpanLogging(true)
+
+	if _, err := server.Call(context.Background(), http.MethodGet, "/orders/1", nil, goLoggerClient.RawResponseBodyFormat, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	inbound := server.Sink.Entries(gologgertest.Tag("INBOUND_REQUEST"), gologgertest.Field("net.route", "/orders/:id"))
+	outbound := server.Sink.Entries(gologgertest.Tag("OUTBOUND_REQUEST"), gologgertest.Field("net.url", "/stock"))
+	rootSpan := server.Sink.Entries(gologgertest.Tag("SPAN"), gologgertest.Message("GET /orders/:id"))
+	if len(inbound) != 1 || len(outbound) != 1 || len(rootSpan) != 1 {
+		t.Fatalf("got %d inbound, %d outbound and %d root span entries, want 1 each:\n%v", len(inbound), len(outbound), len(rootSpan), server.Sink.Entries())
+	}
+
+	rootID := rootSpan[0]["span_id"]
+	if _, ok := rootSpan[0]["parent_span_id"]; ok {
+		t.Errorf("root span has a parent: %v", rootSpan[0])
+	}
+	if inbound[0]["span_id"] != rootID {
+		t.Errorf("inbound span_id = %v, want the root span %v", inbound[0]["span_id"], rootID)
+	}
+	if outbound[0]["parent_span_id"] != rootID {
+		t.Errorf("outbound parent_span_id = %v, want the root span %v", outbound[0]["parent_span_id"], rootID)
+	}
+
+	server.Sink.AssertLogged(t,
+		gologgertest.Tag("SPAN"),
+		gologgertest.Field("span_id", outbound[0]["span_id"]),
+		gologgertest.Field("parent_span_id", rootID),
+		gologgertest.Field("span.attributes.response_http_code", http.StatusServiceUnavailable),
+		gologgertest.HasField("processing_time"),
+	)
+	server.Sink.AssertLogged(t,
+		gologgertest.Tag("SPAN"),
+		gologgertest.Field("span_id", rootID),
+		gologgertest.Field("span.attributes.response_http_code", http.StatusOK),
+	)
+}
+
+func TestSpanLinesAreOptIn(t *testing.T) {
+	server := gologgertest.NewEchoServer(t, func(e *echo.Echo) {
+		e.GET("/users/:id", getUser)
+	})
+
+	if _, err := server.Call(context.Background(), http.MethodGet, "/users/1", nil, goLoggerClient.RawResponseBodyFormat, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	server.Sink.AssertNotLogged(t, gologgertest.Tag("SPAN"))
+	server.Sink.AssertLogged(t, gologgertest.Tag("INBOUND_REQUEST"), gologgertest.HasField("span_id"))
+	server.Sink.AssertLogged(t, gologgertest.Tag("OUTBOUND_REQUEST"), gologgertest.HasField("span_id"))
+}
+
+func TestPanicEndsRootSpanWithError(t *testing.T) {
+	server := gologgertest.NewEchoServer(t, func(e *echo.Echo) {
+		e.GET("/boom", func(c echo.Context) error { panic("boom") })
+	})
+	goLogger.SetSpanLogging(true)
+
+	_, _ = server.Call(context.Background(), http.MethodGet, "/boom", nil, goLoggerClient.RawResponseBodyFormat, nil)
+
+	server.Sink.AssertLogged(t, gologgertest.Tag("PANIC"), gologgertest.HasField("span_id"))
+	server.Sink.AssertLogged(t, gologgertest.Tag("SPAN"), gologgertest.Level("ERROR"), gologgertest.Message("GET /boom"))
+}
+
+func TestIncomingTraceContextIsPropagated(t *testing.T) {
+	goLoggerClient.SetPropagationConfig(goLoggerClient.PropagationConfig{TraceContext: true})
+	t.Cleanup(func() { goLoggerClient.SetPropagationConfig(goLoggerClient.DefaultPropagationConfig) })
+
+	headers := make(chan http.Header, 1)
+	downstream := gologgertest.NewServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers <- r.Header.Clone()
+	}))
+	server := gologgertest.NewEchoServer(t, func(e *echo.Echo) {
+		e.GET("/orders", func(c echo.Context) error {
+			request, _ := http.NewRequest(http.MethodGet, downstream.URL, nil)
+			_, _ = goLoggerClient.Call(c.Request().Context(), request, 0, goLoggerClient.RawResponseBodyFormat, nil, nil)
+			return c.NoContent(http.StatusOK)
+		})
+	})
+
+	request, _ := http.NewRequest(http.MethodGet, server.URL+"/orders", nil)
+	request.Header.Set("X-Tracker-ID", "tracker-1")
+	request.Header.Set("traceparent", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+	request.Header.Set("tracestate", "vendor=value")
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+
+	received := <-headers
+	if got := received.Get("X-Tracker-ID"); got != "tracker-1" {
+		t.Errorf("X-Tracker-ID = %q, want tracker-1", got)
+	}
+	if got := received.Get("traceparent"); !strings.HasPrefix(got, "00-0af7651916cd43dd8448eb211c80319c-") || strings.Contains(got, "b7ad6b7169203331") {
+		t.Errorf("traceparent = %q, want the incoming trace ID with a new parent ID", got)
+	}
+	if got := received.Get("tracestate"); got != "vendor=value" {
+		t.Errorf("tracestate = %q, want vendor=value", got)
+	}
+}
+
+func getUser(c echo.Context) error {
+	return c.JSON(http.StatusOK, map[string]string{"id": c.Param("id")})
+}
+
+func TestTrackerIDIsLoggedAndPropagated(t *testing.T) {
+	server := gologgertest.NewEchoServer(t, func(e *echo.Echo) {
+		e.GET("/users/:id", getUser)
+	})
+
+	for _, incoming := range []string{"", "tracker-1", "invalid tracker id"} {
+		server.Sink.Reset()
+		request, _ := http.NewRequest(http.MethodGet, server.URL+"/users/1", nil)
+		if incoming != "" {
+			request.Header.Set("X-Tracker-ID", incoming)
+		}
+		response, err := http.DefaultClient.Do(request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		response.Body.Close()
+
+		inbound := server.Sink.Entries(gologgertest.Tag("INBOUND_REQUEST"))
+		if len(inbound) != 1 {
+			t.Fatalf("incoming %q: got %d inbound entries, want 1", incoming, len(inbound))
+		}
+		trackerID, _ := inbound[0]["tracker_id"].(string)
+		switch {
+		case incoming == "tracker-1" && trackerID != incoming:
+			t.Errorf("got tracker_id %q, want the incoming %q", trackerID, incoming)
+		case incoming != "tracker-1" && (trackerID == "" || trackerID == incoming):
+			t.Errorf("incoming %q: got tracker_id %q, want a generated one", incoming, trackerID)
+		}
+		server.Sink.AssertLogged(t, gologgertest.Tag("INBOUND_REQUEST"), gologgertest.TrackerID(trackerID), gologgertest.HasField("span_id"))
+	}
+}
+
+func TestAPILogHandlerLogsInboundRequest(t *testing.T) {
+	server := gologgertest.NewEchoServer(t, func(e *echo.Echo) {
+		e.GET("/users/:id", getUser)
+	})
+
+	request, _ := http.NewRequest(http.MethodGet, server.URL+"/users/7", nil)
+	request.Header.Set("Authorization", "Bearer secret")
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+
+	entries := server.Sink.Entries(
+		gologgertest.Tag("INBOUND_REQUEST"),
+		gologgertest.Message("api_info"),
+		gologgertest.Field("net.route", "/users/:id"),
+		gologgertest.Field("net.url", "/users/7"),
+		gologgertest.Field("net.response_http_code", http.StatusOK),
+		gologgertest.HasField("processing_time"),
+	)
+	if len(entries) != 1 {
+		t.Fatalf("got %d inbound entries, want 1:\n%v", len(entries), server.Sink.Entries())
+	}
+	body, _ := entries[0].Field("net.response")
+	if s, _ := body.(string); !strings.Contains(s, `"id":"7"`) {
+		t.Errorf("got response %q, want the response body", s)
+	}
+	header, _ := entries[0].Field("net.request_header")
+	if s, _ := header.(string); strings.Contains(s, "secret") {
+		t.Errorf("Authorization is not hidden in the request header:\n%s", s)
+	}
+}
@@ -85,6 +85,7 @@ func recoverWithConfig(config RecoverConfig) echo.MiddlewareFunc {
 						tranckerID, _ := c.Get("tracker_id").(string)
 						logger := goLogger.NewLog("PANIC")
 						logger.SetTrackerID(tranckerID)
+						logger.SetContext(c.Request().Context())
 
 						msg := fmt.Sprintf("[PANIC RECOVER] %v %s\n", err, stack[:length])
 